@@ -0,0 +1,222 @@
+package scientist
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a candidate's circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed means that the candidate runs normally.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means that the candidate doesn't run.
+	CircuitOpen
+	// CircuitHalfOpen means that the candidate runs
+	// once to decide if the circuit can be closed again.
+	CircuitHalfOpen
+)
+
+// String returns the name of the circuit state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultWindow is the number of runs of a candidate used to check
+// the limits of circuit breakers that don't set their Window.
+const DefaultWindow = 100
+
+// CircuitBreaker stops running candidates that misbehave.
+// It keeps a circuit for every candidate that trips, or opens, when the
+// last runs of the candidate in the window exceed any of the limits.
+// Limits set to zero are not checked. Open circuits let the candidate
+// run once after the cool down, their circuit closes again
+// if that run doesn't exceed any limit.
+// See Facts.SetCircuitBreaker.
+type CircuitBreaker struct {
+	// Window is the number of runs of a candidate used to check
+	// the limits. Zero means DefaultWindow.
+	Window int
+	// MaxErrorRate is the rate of runs, between 0 and 1, where
+	// the candidate can fail, or time out, when the control doesn't.
+	MaxErrorRate float64
+	// MaxPanics is the number of runs where the candidate can panic.
+	MaxPanics int
+	// MaxLatencyRatio is how many times slower
+	// than the control the candidate can be on average.
+	MaxLatencyRatio float64
+	// CoolDown is how long an open circuit
+	// waits before letting the candidate run again.
+	CoolDown time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state    CircuitState
+	openedAt time.Time
+	trial    bool
+	runs     []circuitRun
+}
+
+type circuitRun struct {
+	failed    bool
+	panicked  bool
+	control   time.Duration
+	candidate time.Duration
+}
+
+// State returns the state of a candidate's circuit.
+func (b *CircuitBreaker) State(name string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if c, ok := b.circuits[name]; ok {
+		return c.state
+	}
+	return CircuitClosed
+}
+
+// allow returns true if a candidate can run.
+// Open circuits become half open after the cool down,
+// and they allow only one run until it's recorded.
+func (b *CircuitBreaker) allow(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[name]
+	if !ok {
+		return true
+	}
+
+	switch c.state {
+	case CircuitOpen:
+		if time.Since(c.openedAt) < b.CoolDown {
+			return false
+		}
+		c.state = CircuitHalfOpen
+		c.trial = true
+		return true
+	case CircuitHalfOpen:
+		if c.trial {
+			return false
+		}
+		c.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// filter removes the candidates that are not allowed to run from the behaviors.
+// It returns the behaviors that can run and the names of the candidates removed.
+func (b *CircuitBreaker) filter(behaviors []string) (allowed, skipped []string) {
+	for _, name := range behaviors {
+		if name == "__control__" || b.allow(name) {
+			allowed = append(allowed, name)
+		} else {
+			skipped = append(skipped, name)
+		}
+	}
+	return allowed, skipped
+}
+
+// record adds the candidates' observations to their circuits.
+// It returns the names of the candidates whose circuits tripped.
+func (b *CircuitBreaker) record(control *Observation, candidates []*Observation) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.circuits == nil {
+		b.circuits = make(map[string]*circuit)
+	}
+
+	var tripped []string
+	for _, o := range candidates {
+		c, ok := b.circuits[o.Name]
+		if !ok {
+			c = &circuit{}
+			b.circuits[o.Name] = c
+		}
+
+		run := circuitRun{
+			failed:    o.Error != nil && control.Error == nil,
+			panicked:  IsRecoverFromBadBehavior(o.Error),
+			control:   control.Duration,
+			candidate: o.Duration,
+		}
+
+		if c.state == CircuitHalfOpen {
+			c.trial = false
+			c.runs = nil
+		}
+
+		c.runs = append(c.runs, run)
+		if window := b.window(); len(c.runs) > window {
+			c.runs = c.runs[len(c.runs)-window:]
+		}
+
+		if b.exceeded(c) {
+			if c.state != CircuitOpen {
+				tripped = append(tripped, o.Name)
+			}
+			c.state = CircuitOpen
+			c.openedAt = time.Now()
+			c.runs = nil
+		} else if c.state == CircuitHalfOpen {
+			c.state = CircuitClosed
+		}
+	}
+
+	return tripped
+}
+
+// exceeded returns true if the runs in the circuit exceed any limit.
+// Error rates and latency ratios are checked only when the window is
+// full, except for half open circuits, where the last run decides.
+func (b *CircuitBreaker) exceeded(c *circuit) bool {
+	var failed, panicked int
+	var control, candidate time.Duration
+	for _, r := range c.runs {
+		if r.failed {
+			failed++
+		}
+		if r.panicked {
+			panicked++
+		}
+		control += r.control
+		candidate += r.candidate
+	}
+
+	if b.MaxPanics > 0 && (panicked >= b.MaxPanics || c.state == CircuitHalfOpen && panicked > 0) {
+		return true
+	}
+
+	if c.state != CircuitHalfOpen && len(c.runs) < b.window() {
+		return false
+	}
+
+	if b.MaxErrorRate > 0 && float64(failed)/float64(len(c.runs)) > b.MaxErrorRate {
+		return true
+	}
+
+	return b.MaxLatencyRatio > 0 && control > 0 && float64(candidate)/float64(control) > b.MaxLatencyRatio
+}
+
+func (b *CircuitBreaker) window() int {
+	if b.Window > 0 {
+		return b.Window
+	}
+	return DefaultWindow
+}
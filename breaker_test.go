@@ -0,0 +1,187 @@
+package scientist
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	b := &CircuitBreaker{Window: 4, MaxErrorRate: 0.5, CoolDown: time.Hour}
+	control := &Observation{}
+
+	for i, failed := range []bool{false, true, false, true} {
+		o := &Observation{Name: "test"}
+		if failed {
+			o.Error = errors.New("fail")
+		}
+
+		if tripped := b.record(control, []*Observation{o}); len(tripped) != 0 {
+			t.Fatalf("run %d: got tripped %v, expected none", i, tripped)
+		}
+	}
+
+	tripped := b.record(control, []*Observation{{Name: "test", Error: errors.New("fail")}})
+	if len(tripped) != 1 || tripped[0] != "test" {
+		t.Fatalf("got tripped %v, expected test", tripped)
+	}
+
+	if s := b.State("test"); s != CircuitOpen {
+		t.Fatalf("state got %s, expected %s", s, CircuitOpen)
+	}
+
+	if b.allow("test") {
+		t.Fatal("expected open circuit to not allow runs")
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	b := &CircuitBreaker{MaxPanics: 1}
+	control := &Observation{}
+	panicked := &Observation{Name: "test", Error: &PanicError{Name: "test"}}
+
+	if tripped := b.record(control, []*Observation{panicked}); len(tripped) != 1 {
+		t.Fatalf("got tripped %v, expected test", tripped)
+	}
+
+	if !b.allow("test") {
+		t.Fatal("expected half open circuit to allow one run")
+	}
+
+	if b.allow("test") {
+		t.Fatal("expected half open circuit to allow only one run")
+	}
+
+	b.record(control, []*Observation{{Name: "test"}})
+	if s := b.State("test"); s != CircuitClosed {
+		t.Fatalf("state got %s, expected %s", s, CircuitClosed)
+	}
+}
+
+func TestCircuitBreakerLatency(t *testing.T) {
+	b := &CircuitBreaker{Window: 2, MaxLatencyRatio: 10, CoolDown: time.Hour}
+	control := &Observation{Duration: time.Millisecond}
+
+	b.record(control, []*Observation{{Name: "test", Duration: 5 * time.Millisecond}})
+	tripped := b.record(control, []*Observation{{Name: "test", Duration: 50 * time.Millisecond}})
+	if len(tripped) != 1 {
+		t.Fatalf("got tripped %v, expected test", tripped)
+	}
+}
+
+func TestCircuitBreakerDefaultWindow(t *testing.T) {
+	b := &CircuitBreaker{MaxErrorRate: 0.5}
+	control := &Observation{}
+
+	for i := 0; i < 1000; i++ {
+		b.record(control, []*Observation{{Name: "test"}})
+	}
+
+	if n := len(b.circuits["test"].runs); n != DefaultWindow {
+		t.Fatalf("runs got %d, expected %d", n, DefaultWindow)
+	}
+}
+
+func TestRunCircuitBreaker(t *testing.T) {
+	e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+	e.SetCircuitBreaker(&CircuitBreaker{MaxPanics: 1, CoolDown: time.Hour})
+
+	e.Use(func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	e.Try("bad", func(_ context.Context) (interface{}, error) {
+		panic("oh no!")
+	})
+
+	e.Try("good", func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	if _, err := Run(e); err != nil {
+		t.Fatal(err)
+	}
+
+	r := <-e.published
+	if len(r.Tripped) != 1 || r.Tripped[0] != "bad" {
+		t.Fatalf("got tripped %v, expected bad", r.Tripped)
+	}
+
+	if _, err := Run(e); err != nil {
+		t.Fatal(err)
+	}
+
+	r = <-e.published
+	if len(r.Skipped) != 1 || r.Skipped[0] != "bad" {
+		t.Fatalf("got skipped %v, expected bad", r.Skipped)
+	}
+
+	if len(r.Candidates) != 1 || r.Candidates[0].Name != "good" {
+		t.Fatalf("got %d candidates, expected only good", len(r.Candidates))
+	}
+}
+
+type panicCompareExperiment struct {
+	QuickExperiment
+}
+
+func (e panicCompareExperiment) Compare(ctx context.Context, control, candidate *Observation) bool {
+	panic("compare")
+}
+
+func TestRunCircuitBreakerComparePanics(t *testing.T) {
+	b := &CircuitBreaker{MaxPanics: 1, CoolDown: time.Nanosecond}
+	b.record(&Observation{}, []*Observation{{Name: "test", Error: &PanicError{Name: "test"}}})
+
+	e := panicCompareExperiment{NewQuickExperiment()}
+	e.SetCircuitBreaker(b)
+
+	e.Use(func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	e.Try("test", func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	func() {
+		defer func() { recover() }()
+		Run(e)
+	}()
+
+	if s := b.State("test"); s != CircuitClosed {
+		t.Fatalf("state got %s, expected %s", s, CircuitClosed)
+	}
+}
+
+func TestRunCircuitBreakerAllSkipped(t *testing.T) {
+	e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+	e.SetCircuitBreaker(&CircuitBreaker{MaxPanics: 1, CoolDown: time.Hour})
+
+	e.Use(func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	e.Try("bad", func(_ context.Context) (interface{}, error) {
+		panic("oh no!")
+	})
+
+	Run(e)
+	<-e.published
+
+	v, err := Run(e)
+	if err != nil || v != "success" {
+		t.Fatalf("expected the control value, got %v and %v", v, err)
+	}
+
+	r := <-e.published
+	if len(r.Skipped) != 1 || r.Skipped[0] != "bad" {
+		t.Fatalf("got skipped %v, expected bad", r.Skipped)
+	}
+
+	if len(r.Candidates) != 0 || r.Control.Value != "success" {
+		t.Fatalf("expected a result with only the control, got %v", r)
+	}
+}
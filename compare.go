@@ -0,0 +1,67 @@
+package scientist
+
+import (
+	"errors"
+	"reflect"
+
+	"golang.org/x/net/context"
+)
+
+// Comparator is the type of function that compares the
+// result of the control behavior with the result of a candidate.
+// See Facts.SetComparator and the compare package.
+type Comparator func(ctx context.Context, control, candidate *Observation) bool
+
+// Equal returns true if the values returned by two behaviors are the same.
+// It compares values deeply, with Diff, so it works with values that
+// can't be compared with ==, like slices and maps, and it honors
+// the `scientist` tag in struct fields.
+// Nil values are equal to nil pointers, slices, maps, channels,
+// functions and interfaces of any type. Nil slices and maps are
+// different from empty ones, see NilAsEmpty to consider them equal.
+// It never panics.
+func Equal(control, candidate interface{}) (equal bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			equal = false
+		}
+	}()
+
+	return len(diff(control, candidate, true)) == 0
+}
+
+// EqualErrors returns true if the errors returned by two behaviors are equivalent.
+// Errors are equivalent when both are nil, when one of them wraps the other,
+// or when both have the same type and the same message.
+// It never panics.
+func EqualErrors(control, candidate error) (equal bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			equal = false
+		}
+	}()
+
+	if control == nil || candidate == nil {
+		return control == nil && candidate == nil
+	}
+
+	if errors.Is(candidate, control) || errors.Is(control, candidate) {
+		return true
+	}
+
+	return reflect.TypeOf(control) == reflect.TypeOf(candidate) && control.Error() == candidate.Error()
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Package compare provides comparators to use in experiments
+instead of overriding QuickExperiment.Compare.
+
+Comparators that compare values ignore the errors returned by the behaviors,
+and comparators that compare errors ignore the values. Combine them with
+And, Or, Not and OnField:
+
+	experiment := scientist.NewQuickExperiment()
+	experiment.SetComparator(compare.And(
+		compare.OnField("Items", compare.Unordered),
+		compare.OnField("Total", compare.FloatTolerance(0.01)),
+		compare.ErrorsIs,
+	))
+*/
+package compare
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// DeepEqual returns true if the values are deeply equal.
+// See scientist.Equal.
+func DeepEqual(ctx context.Context, control, candidate *scientist.Observation) bool {
+	return scientist.Equal(control.Value, candidate.Value)
+}
+
+// JSONEqual returns true if the values have the same JSON representation,
+// regardless of their types, the order of their keys and their format.
+// Values that can't be represented as JSON are not equal.
+func JSONEqual(ctx context.Context, control, candidate *scientist.Observation) bool {
+	a, err := normalizeJSON(control.Value)
+	if err != nil {
+		return false
+	}
+
+	b, err := normalizeJSON(candidate.Value)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func normalizeJSON(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
+
+// FloatTolerance returns a comparator that considers floating point
+// numbers equal when their difference is less or equal than epsilon.
+// Numbers are compared anywhere inside the values, see scientist.Diff.
+func FloatTolerance(epsilon float64) scientist.Comparator {
+	return tolerate(func(a, b reflect.Value) bool {
+		if a.Kind() != reflect.Float32 && a.Kind() != reflect.Float64 {
+			return false
+		}
+		return math.Abs(a.Float()-b.Float()) <= epsilon
+	})
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// TimeTolerance returns a comparator that considers times and durations
+// equal when their difference is less or equal than tolerance.
+// Times are compared anywhere inside the values, see scientist.Diff.
+func TimeTolerance(tolerance time.Duration) scientist.Comparator {
+	return tolerate(func(a, b reflect.Value) bool {
+		var d time.Duration
+		switch a.Type() {
+		case timeType:
+			d = a.Interface().(time.Time).Sub(b.Interface().(time.Time))
+		case durationType:
+			d = time.Duration(a.Int() - b.Int())
+		default:
+			return false
+		}
+		return d <= tolerance && d >= -tolerance
+	})
+}
+
+// EqualFold returns true if the values are equal,
+// comparing strings without taking case into account.
+// Strings are compared anywhere inside the values, see scientist.Diff.
+func EqualFold(ctx context.Context, control, candidate *scientist.Observation) bool {
+	return tolerate(func(a, b reflect.Value) bool {
+		return a.Kind() == reflect.String && strings.EqualFold(a.String(), b.String())
+	})(ctx, control, candidate)
+}
+
+// tolerate returns a comparator that considers the values equal when every
+// difference between them is accepted by the function. The function only
+// receives values of the same type.
+func tolerate(accept func(a, b reflect.Value) bool) scientist.Comparator {
+	return func(ctx context.Context, control, candidate *scientist.Observation) bool {
+		for _, d := range scientist.Diff(control.Value, candidate.Value) {
+			a, b := reflect.ValueOf(d.Control), reflect.ValueOf(d.Candidate)
+			if d.Kind != scientist.Changed || !a.IsValid() || !b.IsValid() || a.Type() != b.Type() || !accept(a, b) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Unordered returns true if the values are slices or arrays
+// with the same elements, in any order. Elements are compared
+// with scientist.Equal. Other values are compared with DeepEqual.
+func Unordered(ctx context.Context, control, candidate *scientist.Observation) bool {
+	a, b := reflect.ValueOf(control.Value), reflect.ValueOf(candidate.Value)
+	if !isList(a) || !isList(b) {
+		return DeepEqual(ctx, control, candidate)
+	}
+
+	if a.Type() != b.Type() || a.Len() != b.Len() {
+		return false
+	}
+
+	used := make([]bool, b.Len())
+	for i := 0; i < a.Len(); i++ {
+		found := false
+		for j := 0; j < b.Len(); j++ {
+			if !used[j] && scientist.Equal(a.Index(i).Interface(), b.Index(j).Interface()) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func isList(v reflect.Value) bool {
+	return v.IsValid() && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array)
+}
+
+// ErrorsIs returns true if both errors are nil
+// or one of them wraps the other, see errors.Is.
+func ErrorsIs(ctx context.Context, control, candidate *scientist.Observation) bool {
+	if control.Error == nil || candidate.Error == nil {
+		return control.Error == nil && candidate.Error == nil
+	}
+	return errors.Is(candidate.Error, control.Error) || errors.Is(control.Error, candidate.Error)
+}
+
+// ErrorMessage returns true if both errors are nil
+// or they have the same message.
+func ErrorMessage(ctx context.Context, control, candidate *scientist.Observation) bool {
+	if control.Error == nil || candidate.Error == nil {
+		return control.Error == nil && candidate.Error == nil
+	}
+	return control.Error.Error() == candidate.Error.Error()
+}
+
+// ErrorType returns true if both errors are nil
+// or they have the same type.
+func ErrorType(ctx context.Context, control, candidate *scientist.Observation) bool {
+	return reflect.TypeOf(control.Error) == reflect.TypeOf(candidate.Error)
+}
+
+// ErrorNil returns true if both errors are nil or both are not nil.
+func ErrorNil(ctx context.Context, control, candidate *scientist.Observation) bool {
+	return (control.Error == nil) == (candidate.Error == nil)
+}
+
+// And returns a comparator that returns true
+// if all the comparators return true.
+func And(comparators ...scientist.Comparator) scientist.Comparator {
+	return func(ctx context.Context, control, candidate *scientist.Observation) bool {
+		for _, c := range comparators {
+			if !c(ctx, control, candidate) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a comparator that returns true
+// if any of the comparators returns true.
+func Or(comparators ...scientist.Comparator) scientist.Comparator {
+	return func(ctx context.Context, control, candidate *scientist.Observation) bool {
+		for _, c := range comparators {
+			if c(ctx, control, candidate) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns a comparator that returns
+// the opposite of the given comparator.
+func Not(comparator scientist.Comparator) scientist.Comparator {
+	return func(ctx context.Context, control, candidate *scientist.Observation) bool {
+		return !comparator(ctx, control, candidate)
+	}
+}
+
+// OnField returns a comparator that compares only a field of the values.
+// The path is a list of exported struct fields or string map keys separated
+// by dots, like `Order.Total`. Pointers and interfaces are followed.
+// The comparator receives copies of the observations where the values
+// are the fields. Values without the field, or with nil embedded
+// pointers in the way, are not equal.
+func OnField(path string, comparator scientist.Comparator) scientist.Comparator {
+	fields := strings.Split(path, ".")
+
+	return func(ctx context.Context, control, candidate *scientist.Observation) bool {
+		a, ok := field(control.Value, fields)
+		if !ok {
+			return false
+		}
+
+		b, ok := field(candidate.Value, fields)
+		if !ok {
+			return false
+		}
+
+		co, ca := *control, *candidate
+		co.Value, ca.Value = a, b
+		return comparator(ctx, &co, &ca)
+	}
+}
+
+func field(value interface{}, fields []string) (interface{}, bool) {
+	v := reflect.ValueOf(value)
+	for _, name := range fields {
+		for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+			v = v.Elem()
+		}
+
+		switch {
+		case !v.IsValid():
+			return nil, false
+		case v.Kind() == reflect.Struct:
+			f, ok := v.Type().FieldByName(name)
+			if !ok {
+				return nil, false
+			}
+			// unlike FieldByName, FieldByIndexErr doesn't
+			// panic through nil embedded pointers.
+			fv, err := v.FieldByIndexErr(f.Index)
+			if err != nil || !fv.CanInterface() {
+				return nil, false
+			}
+			v = fv
+		case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+			v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return nil, false
+			}
+		default:
+			return nil, false
+		}
+	}
+	return v.Interface(), true
+}
@@ -0,0 +1,164 @@
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+type invoice struct {
+	ID      string
+	Total   float64
+	Items   []string
+	Created time.Time
+}
+
+func observations(control, candidate interface{}) (*scientist.Observation, *scientist.Observation) {
+	return &scientist.Observation{Value: control}, &scientist.Observation{Value: candidate}
+}
+
+func TestValueComparators(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name       string
+		comparator scientist.Comparator
+		control    interface{}
+		candidate  interface{}
+		equal      bool
+	}{
+		{"deep equal", DeepEqual, []int{1, 2}, []int{1, 2}, true},
+		{"deep equal", DeepEqual, []int{1, 2}, []int{2, 1}, false},
+		{"json equal", JSONEqual, map[string]int{"a": 1}, struct{ A int }{1}, false},
+		{"json equal", JSONEqual, map[string]int{"A": 1}, struct{ A int }{1}, true},
+		{"json equal", JSONEqual, make(chan int), make(chan int), false},
+		{"float tolerance", FloatTolerance(0.01), invoice{Total: 1.001}, invoice{Total: 1.002}, true},
+		{"float tolerance", FloatTolerance(0.01), invoice{Total: 1}, invoice{Total: 1.1}, false},
+		{"float tolerance", FloatTolerance(0.01), invoice{ID: "a"}, invoice{ID: "b"}, false},
+		{"time tolerance", TimeTolerance(time.Second), invoice{Created: now}, invoice{Created: now.Add(time.Millisecond)}, true},
+		{"time tolerance", TimeTolerance(time.Second), invoice{Created: now}, invoice{Created: now.Add(time.Minute)}, false},
+		{"time tolerance", TimeTolerance(time.Second), time.Second, 2 * time.Second, true},
+		{"unordered", Unordered, []string{"a", "b", "b"}, []string{"b", "a", "b"}, true},
+		{"unordered", Unordered, []string{"a", "b", "b"}, []string{"a", "a", "b"}, false},
+		{"unordered", Unordered, "a", "a", true},
+		{"equal fold", EqualFold, invoice{ID: "ABC"}, invoice{ID: "abc"}, true},
+		{"equal fold", EqualFold, invoice{ID: "ABC"}, invoice{ID: "abd"}, false},
+	}
+
+	ctx := context.Background()
+	for _, c := range cases {
+		control, candidate := observations(c.control, c.candidate)
+		if g := c.comparator(ctx, control, candidate); g != c.equal {
+			t.Fatalf("%s %v and %v: got %v, expected %v", c.name, c.control, c.candidate, g, c.equal)
+		}
+	}
+}
+
+type codeError struct {
+	code int
+}
+
+func (e codeError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func TestErrorComparators(t *testing.T) {
+	base := errors.New("not found")
+
+	cases := []struct {
+		name       string
+		comparator scientist.Comparator
+		control    error
+		candidate  error
+		equal      bool
+	}{
+		{"errors is", ErrorsIs, nil, nil, true},
+		{"errors is", ErrorsIs, base, fmt.Errorf("wrapped: %w", base), true},
+		{"errors is", ErrorsIs, base, errors.New("not found"), false},
+		{"error message", ErrorMessage, base, errors.New("not found"), true},
+		{"error message", ErrorMessage, base, nil, false},
+		{"error type", ErrorType, codeError{1}, codeError{2}, true},
+		{"error type", ErrorType, codeError{1}, base, false},
+		{"error nil", ErrorNil, codeError{1}, base, true},
+		{"error nil", ErrorNil, nil, base, false},
+	}
+
+	ctx := context.Background()
+	for _, c := range cases {
+		control := &scientist.Observation{Error: c.control}
+		candidate := &scientist.Observation{Error: c.candidate}
+		if g := c.comparator(ctx, control, candidate); g != c.equal {
+			t.Fatalf("%s %v and %v: got %v, expected %v", c.name, c.control, c.candidate, g, c.equal)
+		}
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	ctx := context.Background()
+
+	c := And(
+		OnField("Items", Unordered),
+		OnField("Total", FloatTolerance(0.01)),
+		ErrorNil,
+	)
+
+	control, candidate := observations(
+		&invoice{ID: "1", Total: 10, Items: []string{"a", "b"}},
+		&invoice{ID: "2", Total: 10.001, Items: []string{"b", "a"}},
+	)
+
+	if !c(ctx, control, candidate) {
+		t.Fatal("expected invoices to match")
+	}
+
+	candidate.Error = errors.New("fail")
+	if c(ctx, control, candidate) {
+		t.Fatal("expected invoices with different errors to not match")
+	}
+
+	if !Or(DeepEqual, ErrorNil)(ctx, control, control) {
+		t.Fatal("expected or to match")
+	}
+
+	if Not(DeepEqual)(ctx, control, control) {
+		t.Fatal("expected not to negate the comparator")
+	}
+
+	if OnField("Missing", DeepEqual)(ctx, control, control) {
+		t.Fatal("expected missing fields to not match")
+	}
+
+	embedded, present := observations(struct{ *invoice }{}, struct{ *invoice }{&invoice{Total: 1}})
+	if OnField("Total", DeepEqual)(ctx, embedded, present) || OnField("Total", DeepEqual)(ctx, present, embedded) {
+		t.Fatal("expected fields behind nil embedded pointers to not match")
+	}
+
+	nested, other := observations(map[string]interface{}{"invoice": invoice{Total: 1}}, map[string]interface{}{"invoice": invoice{Total: 1}})
+	if !OnField("invoice.Total", DeepEqual)(ctx, nested, other) {
+		t.Fatal("expected nested fields to match")
+	}
+}
+
+func TestSetComparator(t *testing.T) {
+	e := scientist.NewQuickExperiment()
+	e.SetComparator(Unordered)
+
+	scientist.ErrorOnMismatch = true
+	defer func() { scientist.ErrorOnMismatch = false }()
+
+	e.Use(func(_ context.Context) (interface{}, error) {
+		return []string{"1", "2"}, nil
+	})
+
+	e.Try("test", func(_ context.Context) (interface{}, error) {
+		return []string{"2", "1"}, nil
+	})
+
+	if _, err := scientist.Run(e); err != nil {
+		t.Fatal(err)
+	}
+}
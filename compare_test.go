@@ -0,0 +1,68 @@
+package scientist
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type uncomparableError []string
+
+func (e uncomparableError) Error() string {
+	return fmt.Sprint([]string(e))
+}
+
+func TestEqual(t *testing.T) {
+	var nilSlice []string
+	var nilMap map[string]int
+
+	cases := []struct {
+		control   interface{}
+		candidate interface{}
+		equal     bool
+	}{
+		{nil, nil, true},
+		{true, true, true},
+		{1, 2, false},
+		{1, int64(1), false},
+		{[]string{"1", "2"}, []string{"1", "2"}, true},
+		{[]string{"1", "2"}, []string{"2", "1"}, false},
+		{map[string][]int{"a": {1}}, map[string][]int{"a": {1}}, true},
+		{struct{ s []int }{[]int{1}}, struct{ s []int }{[]int{1}}, true},
+		{nil, nilSlice, true},
+		{nilMap, nil, true},
+		{nil, []string{}, false},
+	}
+
+	for _, c := range cases {
+		if g := Equal(c.control, c.candidate); g != c.equal {
+			t.Fatalf("equal %#v and %#v: got %v, expected %v", c.control, c.candidate, g, c.equal)
+		}
+	}
+}
+
+func TestEqualErrors(t *testing.T) {
+	base := errors.New("not found")
+
+	cases := []struct {
+		control   error
+		candidate error
+		equal     bool
+	}{
+		{nil, nil, true},
+		{base, nil, false},
+		{nil, base, false},
+		{base, base, true},
+		{fmt.Errorf("failed %d", 1), fmt.Errorf("failed %d", 1), true},
+		{fmt.Errorf("failed %d", 1), fmt.Errorf("failed %d", 2), false},
+		{base, fmt.Errorf("wrapped: %w", base), true},
+		{errors.New("failed"), uncomparableError{"failed"}, false},
+		{uncomparableError{"a"}, uncomparableError{"a"}, true},
+	}
+
+	for _, c := range cases {
+		if g := EqualErrors(c.control, c.candidate); g != c.equal {
+			t.Fatalf("equal errors %v and %v: got %v, expected %v", c.control, c.candidate, g, c.equal)
+		}
+	}
+}
@@ -0,0 +1,471 @@
+package scientist
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DiffKind is the kind of a difference between two values.
+type DiffKind int
+
+const (
+	// Changed means that the values are different.
+	Changed DiffKind = iota
+	// Added means that only the candidate has a value,
+	// like a map key or a slice element.
+	Added
+	// Removed means that only the control has a value.
+	Removed
+	// TypeChanged means that the values have different types.
+	TypeChanged
+)
+
+// String returns the name of the difference kind.
+func (k DiffKind) String() string {
+	switch k {
+	case Changed:
+		return "changed"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case TypeChanged:
+		return "type changed"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the difference kind as its name.
+func (k DiffKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a difference kind from its name.
+func (k *DiffKind) UnmarshalText(text []byte) error {
+	for _, kind := range []DiffKind{Changed, Added, Removed, TypeChanged} {
+		if kind.String() == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown difference kind: %s", text)
+}
+
+// Difference is a difference between the value returned by
+// the control behavior and the value returned by a candidate.
+type Difference struct {
+	// Path is the location of the difference inside the values,
+	// like `.Items[3].Price` or `["key"]`. It's `.` for the values themselves.
+	Path string
+	// Kind is the kind of difference.
+	Kind DiffKind
+	// Control is the value of the control at the path, if any.
+	Control interface{}
+	// Candidate is the value of the candidate at the path, if any.
+	Candidate interface{}
+}
+
+// String returns the string representation of the difference.
+// Differences without values, like the ones of experiments
+// that clean their values, only include the path and the kind.
+func (d Difference) String() string {
+	if d.Control == nil && d.Candidate == nil {
+		return fmt.Sprintf("%s: %s", d.Path, d.Kind)
+	}
+
+	switch d.Kind {
+	case Added:
+		return fmt.Sprintf("%s: added %v", d.Path, d.Candidate)
+	case Removed:
+		return fmt.Sprintf("%s: removed %v", d.Path, d.Control)
+	case TypeChanged:
+		return fmt.Sprintf("%s: %T(%v) != %T(%v)", d.Path, d.Control, d.Control, d.Candidate, d.Candidate)
+	default:
+		return fmt.Sprintf("%s: %v != %v", d.Path, d.Control, d.Candidate)
+	}
+}
+
+// Diff walks the values returned by the control behavior and a candidate,
+// and returns their differences. It walks structs, maps, slices, arrays,
+// pointers and interfaces. Values with an `Equal` method, like time.Time,
+// are compared with that method.
+// Nil values are equal to nil pointers, slices, maps, channels,
+// functions and interfaces of any type. Nil slices and maps are
+// different from empty ones, see NilAsEmpty to consider them equal.
+//
+// Struct fields can change how they're compared with the `scientist` tag.
+// Fields tagged with `scientist:"-"` are not compared. Fields tagged with
+// a tolerance, like `scientist:"tolerance=1ms"` or `scientist:"tolerance=0.01"`,
+// are equal when the difference between their times, durations or
+// numbers is less or equal than the tolerance.
+func Diff(control, candidate interface{}) []Difference {
+	return diff(control, candidate, false)
+}
+
+func diff(control, candidate interface{}, quick bool) []Difference {
+	if control == nil || candidate == nil {
+		if isNil(control) && isNil(candidate) {
+			return nil
+		}
+		return []Difference{{Path: ".", Kind: Changed, Control: control, Candidate: candidate}}
+	}
+
+	d := &differ{visited: make(map[visit]bool), quick: quick}
+	d.diff("", reflect.ValueOf(control), reflect.ValueOf(candidate))
+	return d.diffs
+}
+
+type visit struct {
+	control   uintptr
+	candidate uintptr
+	typ       reflect.Type
+}
+
+type differ struct {
+	diffs   []Difference
+	visited map[visit]bool
+	// quick stops walking the values after the first difference.
+	quick bool
+}
+
+func (d *differ) add(path string, kind DiffKind, control, candidate reflect.Value) {
+	if path == "" {
+		path = "."
+	}
+	d.diffs = append(d.diffs, Difference{
+		Path:      path,
+		Kind:      kind,
+		Control:   valueOf(control),
+		Candidate: valueOf(candidate),
+	})
+}
+
+func (d *differ) diff(path string, control, candidate reflect.Value) {
+	if d.quick && len(d.diffs) > 0 {
+		return
+	}
+
+	if !control.IsValid() || !candidate.IsValid() {
+		if control.IsValid() != candidate.IsValid() {
+			d.add(path, Changed, control, candidate)
+		}
+		return
+	}
+
+	if control.Type() != candidate.Type() {
+		d.add(path, TypeChanged, control, candidate)
+		return
+	}
+
+	if equal, ok := equalMethod(control, candidate); ok {
+		if !equal {
+			d.add(path, Changed, control, candidate)
+		}
+		return
+	}
+
+	switch control.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if control.IsNil() || candidate.IsNil() {
+			if control.IsNil() != candidate.IsNil() {
+				d.diffNil(path, control, candidate)
+			}
+			return
+		}
+
+		v := visit{control.Pointer(), candidate.Pointer(), control.Type()}
+		if d.visited[v] {
+			return
+		}
+		d.visited[v] = true
+	}
+
+	switch control.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if control.IsNil() || candidate.IsNil() {
+			if control.IsNil() != candidate.IsNil() {
+				d.add(path, Changed, control, candidate)
+			}
+			return
+		}
+		d.diff(path, control.Elem(), candidate.Elem())
+	case reflect.Struct:
+		t := control.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := parseTag(f.Tag.Get("scientist"))
+			if tag.ignore {
+				continue
+			}
+
+			a, b := control.Field(i), candidate.Field(i)
+			if tag.tolerance != nil && tag.tolerance.within(a, b) {
+				continue
+			}
+			d.diff(path+"."+f.Name, a, b)
+		}
+	case reflect.Slice, reflect.Array:
+		n := control.Len()
+		if candidate.Len() < n {
+			n = candidate.Len()
+		}
+		for i := 0; i < n; i++ {
+			d.diff(fmt.Sprintf("%s[%d]", path, i), control.Index(i), candidate.Index(i))
+		}
+		for i := n; i < control.Len(); i++ {
+			d.add(fmt.Sprintf("%s[%d]", path, i), Removed, control.Index(i), reflect.Value{})
+		}
+		for i := n; i < candidate.Len(); i++ {
+			d.add(fmt.Sprintf("%s[%d]", path, i), Added, reflect.Value{}, candidate.Index(i))
+		}
+	case reflect.Map:
+		for _, k := range mapKeys(control, candidate) {
+			p := path + mapIndex(k)
+			a, b := control.MapIndex(k), candidate.MapIndex(k)
+			switch {
+			case !b.IsValid():
+				d.add(p, Removed, a, b)
+			case !a.IsValid():
+				d.add(p, Added, a, b)
+			default:
+				d.diff(p, a, b)
+			}
+		}
+	default:
+		if !equalLeaf(control, candidate) {
+			d.add(path, Changed, control, candidate)
+		}
+	}
+}
+
+// diffNil adds the difference between a nil and a non nil value.
+// Nil maps and slices are different from empty ones, like `null` and
+// `[]` in JSON. The elements of non empty ones are added or removed.
+// See NilAsEmpty to consider them equal.
+func (d *differ) diffNil(path string, control, candidate reflect.Value) {
+	if control.Kind() == reflect.Ptr || control.Len() == candidate.Len() {
+		d.add(path, Changed, control, candidate)
+		return
+	}
+
+	empty := reflect.MakeSlice(reflect.SliceOf(control.Type().Elem()), 0, 0)
+	if control.Kind() == reflect.Map {
+		empty = reflect.MakeMap(control.Type())
+	}
+
+	if control.IsNil() {
+		d.diff(path, empty, candidate)
+	} else {
+		d.diff(path, control, empty)
+	}
+}
+
+// equalMethod compares two values with their `Equal` method,
+// if their type has one that takes a value of the same type.
+func equalMethod(control, candidate reflect.Value) (bool, bool) {
+	if !control.CanInterface() || !candidate.CanInterface() {
+		return false, false
+	}
+
+	m, ok := control.Type().MethodByName("Equal")
+	if !ok || m.Type.NumIn() != 2 || m.Type.NumOut() != 1 ||
+		m.Type.In(1) != control.Type() || m.Type.Out(0).Kind() != reflect.Bool {
+		return false, false
+	}
+
+	if control.Kind() == reflect.Ptr && (control.IsNil() || candidate.IsNil()) {
+		return control.IsNil() == candidate.IsNil(), true
+	}
+
+	return control.Method(m.Index).Call([]reflect.Value{candidate})[0].Bool(), true
+}
+
+// equalLeaf compares two values that don't have other values
+// inside them. It works with unexported struct fields.
+func equalLeaf(control, candidate reflect.Value) bool {
+	switch control.Kind() {
+	case reflect.Bool:
+		return control.Bool() == candidate.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return control.Int() == candidate.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return control.Uint() == candidate.Uint()
+	case reflect.Float32, reflect.Float64:
+		return control.Float() == candidate.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return control.Complex() == candidate.Complex()
+	case reflect.String:
+		return control.String() == candidate.String()
+	case reflect.Chan, reflect.UnsafePointer:
+		return control.Pointer() == candidate.Pointer()
+	case reflect.Func:
+		return control.IsNil() && candidate.IsNil()
+	default:
+		return false
+	}
+}
+
+// valueOf returns the value inside a reflect.Value.
+// Values of unexported struct fields that can't be
+// returned as interfaces are returned as their basic kinds.
+func valueOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.CanInterface() {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex()
+	case reflect.String:
+		return v.String()
+	default:
+		return v.String()
+	}
+}
+
+// mapKeys returns the keys of both maps, sorted by their
+// string representation so differences are always in the same order.
+func mapKeys(control, candidate reflect.Value) []reflect.Value {
+	seen := make(map[string]bool)
+	var keys []reflect.Value
+	for _, m := range []reflect.Value{control, candidate} {
+		for _, k := range m.MapKeys() {
+			index := mapIndex(k)
+			if !seen[index] {
+				seen[index] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return mapIndex(keys[i]) < mapIndex(keys[j])
+	})
+	return keys
+}
+
+func mapIndex(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return fmt.Sprintf("[%q]", k.String())
+	}
+	return fmt.Sprintf("[%v]", valueOf(k))
+}
+
+// withoutValues returns a copy of the differences
+// without the values of the control and the candidate.
+func withoutValues(diffs []Difference) []Difference {
+	stripped := make([]Difference, len(diffs))
+	for i, d := range diffs {
+		stripped[i] = Difference{Path: d.Path, Kind: d.Kind}
+	}
+	return stripped
+}
+
+// formatDiff returns the string representation of a list of differences.
+func formatDiff(diffs []Difference) string {
+	s := make([]string, len(diffs))
+	for i, d := range diffs {
+		s[i] = d.String()
+	}
+	return strings.Join(s, "; ")
+}
+
+// fieldTag holds the options of the `scientist` struct tag.
+type fieldTag struct {
+	ignore    bool
+	tolerance *tolerance
+}
+
+type tolerance struct {
+	duration time.Duration
+	number   float64
+	// durations and numbers are only
+	// set when the tolerance can be parsed as them.
+	isDuration bool
+	isNumber   bool
+}
+
+func parseTag(tag string) fieldTag {
+	var t fieldTag
+	for _, option := range strings.Split(tag, ",") {
+		option = strings.TrimSpace(option)
+		switch {
+		case option == "-":
+			t.ignore = true
+		case strings.HasPrefix(option, "tolerance="):
+			value := strings.TrimPrefix(option, "tolerance=")
+			tol := &tolerance{}
+			if d, err := time.ParseDuration(value); err == nil {
+				tol.duration, tol.isDuration = d, true
+			}
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				tol.number, tol.isNumber = n, true
+			}
+			if tol.isDuration || tol.isNumber {
+				t.tolerance = tol
+			}
+		}
+	}
+	return t
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// within returns true if the difference between two values of
+// the same type is less or equal than the tolerance.
+func (t *tolerance) within(control, candidate reflect.Value) bool {
+	for control.Kind() == reflect.Ptr {
+		if control.IsNil() || candidate.IsNil() {
+			return control.IsNil() && candidate.IsNil()
+		}
+		control, candidate = control.Elem(), candidate.Elem()
+	}
+
+	switch {
+	case control.Type() == timeType && t.isDuration:
+		if !control.CanInterface() || !candidate.CanInterface() {
+			return false
+		}
+		d := control.Interface().(time.Time).Sub(candidate.Interface().(time.Time))
+		return d <= t.duration && d >= -t.duration
+	case control.Type() == durationType && t.isDuration:
+		d := time.Duration(control.Int() - candidate.Int())
+		return d <= t.duration && d >= -t.duration
+	}
+
+	if !t.isNumber {
+		return false
+	}
+
+	switch control.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return math.Abs(float64(control.Int())-float64(candidate.Int())) <= t.number
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return math.Abs(float64(control.Uint())-float64(candidate.Uint())) <= t.number
+	case reflect.Float32, reflect.Float64:
+		return math.Abs(control.Float()-candidate.Float()) <= t.number
+	default:
+		return false
+	}
+}
@@ -0,0 +1,169 @@
+package scientist
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type item struct {
+	Name  string
+	Price float64
+	tags  []string
+}
+
+type order struct {
+	ID      int
+	Items   []item
+	Meta    map[string]interface{}
+	Created time.Time
+	Parent  *order
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+
+	control := order{
+		ID:      1,
+		Items:   []item{{"a", 10, []string{"x"}}, {"b", 20, nil}},
+		Meta:    map[string]interface{}{"source": "web", "retries": 1},
+		Created: now,
+	}
+
+	candidate := order{
+		ID:      1,
+		Items:   []item{{"a", 10, []string{"y"}}, {"b", 25, []string{}}, {"c", 5, nil}},
+		Meta:    map[string]interface{}{"source": "api", "retries": "1", "new": true},
+		Created: now.In(time.UTC),
+		Parent:  &order{ID: 2},
+	}
+
+	w := []Difference{
+		{Path: ".Items[0].tags[0]", Kind: Changed, Control: "x", Candidate: "y"},
+		{Path: ".Items[1].Price", Kind: Changed, Control: 20.0, Candidate: 25.0},
+		{Path: ".Items[1].tags", Kind: Changed, Control: "<[]string Value>", Candidate: "<[]string Value>"},
+		{Path: ".Items[2]", Kind: Added, Candidate: item{"c", 5, nil}},
+		{Path: `.Meta["new"]`, Kind: Added, Candidate: true},
+		{Path: `.Meta["retries"]`, Kind: TypeChanged, Control: 1, Candidate: "1"},
+		{Path: `.Meta["source"]`, Kind: Changed, Control: "web", Candidate: "api"},
+		{Path: ".Parent", Kind: Changed, Control: (*order)(nil), Candidate: candidate.Parent},
+	}
+
+	g := Diff(control, candidate)
+	if !reflect.DeepEqual(g, w) {
+		t.Fatalf("diff got:\n%v\nexpected:\n%v", g, w)
+	}
+}
+
+func TestDiffEqual(t *testing.T) {
+	cyclic := &order{ID: 1}
+	cyclic.Parent = cyclic
+
+	cases := []struct {
+		control   interface{}
+		candidate interface{}
+	}{
+		{nil, nil},
+		{1, 1},
+		{[]string{"1"}, []string{"1"}},
+		{map[int][]int{1: {1}}, map[int][]int{1: {1}}},
+		{cyclic, cyclic},
+	}
+
+	for _, c := range cases {
+		if g := Diff(c.control, c.candidate); len(g) != 0 {
+			t.Fatalf("diff %#v and %#v: got %v, expected none", c.control, c.candidate, g)
+		}
+	}
+}
+
+func TestDiffNilEmpty(t *testing.T) {
+	cases := []struct {
+		control   interface{}
+		candidate interface{}
+	}{
+		{[]string(nil), []string{}},
+		{map[string]int{}, map[string]int(nil)},
+		{order{Items: nil}, order{Items: []item{}}},
+	}
+
+	for _, c := range cases {
+		if g := Diff(c.control, c.candidate); len(g) != 1 || g[0].Kind != Changed {
+			t.Fatalf("diff %#v and %#v: got %v, expected a change", c.control, c.candidate, g)
+		}
+
+		if Equal(c.control, c.candidate) {
+			t.Fatalf("expected %#v and %#v to not be equal", c.control, c.candidate)
+		}
+	}
+}
+
+func TestDiffRoot(t *testing.T) {
+	g := Diff("success", "fail")
+	w := []Difference{{Path: ".", Kind: Changed, Control: "success", Candidate: "fail"}}
+	if !reflect.DeepEqual(g, w) {
+		t.Fatalf("diff got %v, expected %v", g, w)
+	}
+
+	g = Diff(nil, 1)
+	if len(g) != 1 || g[0].Path != "." {
+		t.Fatalf("diff got %v, expected a difference in the root", g)
+	}
+}
+
+func TestMismatchErrorDiff(t *testing.T) {
+	control := &Observation{Value: []int{1, 2}}
+	candidate := &Observation{Name: "test", Value: []int{1, 3}}
+	candidate.Diff = Diff(control.Value, candidate.Value)
+
+	err := MismatchError{Result{name: "experiment", Control: control, Mistmaches: []*Observation{candidate}}}
+	if g := err.Error(); !strings.Contains(g, "`test` differs at [1]: 2 != 3") {
+		t.Fatalf("got %q, expected the difference", g)
+	}
+}
+
+type taggedResult struct {
+	ID        string        `scientist:"-"`
+	Total     float64       `scientist:"tolerance=0.01"`
+	Count     int           `scientist:"tolerance=1"`
+	Created   time.Time     `scientist:"tolerance=1ms"`
+	Elapsed   time.Duration `scientist:"tolerance=1s"`
+	UpdatedAt *time.Time    `scientist:"tolerance=1ms"`
+	Name      string
+}
+
+func TestDiffTags(t *testing.T) {
+	now := time.Now()
+	later := now.Add(500 * time.Microsecond)
+
+	control := taggedResult{ID: "a", Total: 1.001, Count: 1, Created: now, Elapsed: time.Second, UpdatedAt: &now, Name: "x"}
+	candidate := taggedResult{ID: "b", Total: 1.009, Count: 2, Created: later, Elapsed: 1500 * time.Millisecond, UpdatedAt: &later, Name: "x"}
+
+	if g := Diff(control, candidate); len(g) != 0 {
+		t.Fatalf("diff got %v, expected none", g)
+	}
+
+	if !Equal(control, candidate) {
+		t.Fatal("expected tagged values to be equal")
+	}
+
+	candidate.Total = 1.1
+	candidate.Created = now.Add(time.Second)
+	candidate.Name = "y"
+
+	g := Diff(control, candidate)
+	paths := make([]string, len(g))
+	for i, d := range g {
+		paths[i] = d.Path
+	}
+
+	w := []string{".Total", ".Created", ".Name"}
+	if !reflect.DeepEqual(paths, w) {
+		t.Fatalf("diff paths got %v, expected %v", paths, w)
+	}
+
+	if Equal(control, candidate) {
+		t.Fatal("expected values out of tolerance to not be equal")
+	}
+}
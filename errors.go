@@ -1,6 +1,10 @@
 package scientist
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // IsBehaviorExist returns true if the error
 // was caused because a behavior with a given
@@ -33,16 +37,44 @@ func (e controlDoesNotExist) Error() string {
 
 // IsRecoverFromBadBehavior returns true if one
 // of the behaviors panicked.
+// Use errors.As with a *PanicError to inspect the panic.
 func IsRecoverFromBadBehavior(err error) bool {
-	_, ok := err.(recoverFromBadBehavior)
+	var p *PanicError
+	return errors.As(err, &p)
+}
+
+// PanicError is the error recorded in the
+// observation of a behavior that panicked.
+// It's also returned by publishers that panic, see MultiPublisher,
+// and handled as a publish error when the experiment panics
+// gathering its result in the background.
+type PanicError struct {
+	// Name is the name of the behavior that panicked, the type
+	// of the publisher, or the name of the experiment.
+	Name string
+	// Value is the value given to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine
+	// that panicked, as formatted by debug.Stack.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recover from bad behavior %s: %v", e.Name, e.Value)
+}
+
+// IsTimedOut returns true if one of the
+// behaviors didn't finish before its timeout.
+func IsTimedOut(err error) bool {
+	_, ok := err.(behaviorTimedOut)
 	return ok
 }
 
-type recoverFromBadBehavior struct {
-	name  string
-	value interface{}
+type behaviorTimedOut struct {
+	name    string
+	timeout time.Duration
 }
 
-func (e recoverFromBadBehavior) Error() string {
-	return fmt.Sprintf("recover from bad behavior %s: %v", e.name, e.value)
+func (e behaviorTimedOut) Error() string {
+	return fmt.Sprintf("behavior %s timed out after %v", e.name, e.timeout)
 }
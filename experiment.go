@@ -1,9 +1,29 @@
 package scientist
 
-import "golang.org/x/net/context"
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
 
 // Experiment is an interface that defines
 // how an experiment behaves.
+//
+// Experiments can also implement these optional methods:
+//
+//	// RunIf returns false to run only the control
+//	// behavior, after IsEnabled returns true.
+//	RunIf(ctx context.Context) bool
+//	// BeforeRun is called before running the behaviors,
+//	// only when the experiment runs its candidates.
+//	BeforeRun(ctx context.Context)
+//	// Clean transforms a value returned by a behavior before
+//	// it's published, after it's been compared. It's not
+//	// called with nil values.
+//	Clean(value interface{}) interface{}
+//	// OnPublishError handles the errors returned by
+//	// the publishers, instead of the global OnPublishError.
+//	OnPublishError(ctx context.Context, result Result, err error)
 type Experiment interface {
 	Name() string
 	Control() Behavior
@@ -15,6 +35,207 @@ type Experiment interface {
 	Publish(ctx context.Context, result Result) error
 }
 
+// seededExperiment is implemented by experiments
+// that shuffle their behaviors with a known seed.
+type seededExperiment interface {
+	NextSeed() int64
+	ShuffleWithSeed(seed int64) []string
+}
+
+// shuffle returns the seed of the shuffle,
+// if the experiment has one, and the behaviors.
+func shuffle(e Experiment) (int64, []string) {
+	if s, ok := e.(seededExperiment); ok {
+		seed := s.NextSeed()
+		return seed, s.ShuffleWithSeed(seed)
+	}
+	return 0, e.Shuffle()
+}
+
+// runIfExperiment is implemented by experiments
+// that decide whether to run for every invocation.
+type runIfExperiment interface {
+	RunIf(ctx context.Context) bool
+}
+
+func runIf(ctx context.Context, e Experiment) bool {
+	if r, ok := e.(runIfExperiment); ok {
+		return r.RunIf(ctx)
+	}
+	return true
+}
+
+// beforeRunExperiment is implemented by experiments
+// that need to do some setup before running.
+type beforeRunExperiment interface {
+	BeforeRun(ctx context.Context)
+}
+
+func beforeRun(ctx context.Context, e Experiment) {
+	if b, ok := e.(beforeRunExperiment); ok {
+		b.BeforeRun(ctx)
+	}
+}
+
+// cleanExperiment is implemented by experiments
+// that transform values before publishing them.
+type cleanExperiment interface {
+	Clean(value interface{}) interface{}
+}
+
+// clean transforms a value with the experiment's Clean method. Nil values,
+// returned by behaviors that fail, are not cleaned, and values that make
+// Clean panic are kept as they are, so publishing never panics.
+func clean(e Experiment, value interface{}) (cleaned interface{}) {
+	c, ok := e.(cleanExperiment)
+	if !ok || value == nil {
+		return value
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			cleaned = value
+		}
+	}()
+	return c.Clean(value)
+}
+
+// publishErrorExperiment is implemented by
+// experiments that handle their publish errors.
+type publishErrorExperiment interface {
+	OnPublishError(ctx context.Context, result Result, err error)
+}
+
+func onPublishError(ctx context.Context, e Experiment, result Result, err error) {
+	if p, ok := e.(publishErrorExperiment); ok {
+		p.OnPublishError(ctx, result, err)
+		return
+	}
+	OnPublishError(ctx, result, err)
+}
+
+// repanicExperiment is implemented by experiments
+// that panic again when the control behavior panics.
+// See Facts.SetRepanicControl.
+type repanicExperiment interface {
+	RepanicControl() bool
+}
+
+func repanicControl(e Experiment) bool {
+	r, ok := e.(repanicExperiment)
+	return ok && r.RepanicControl()
+}
+
+// asyncExperiment is implemented by experiments
+// that publish their results in the background.
+// See Facts.SetAsync.
+type asyncExperiment interface {
+	Async() bool
+}
+
+func isAsync(e Experiment) bool {
+	a, ok := e.(asyncExperiment)
+	return ok && a.Async()
+}
+
+// timeoutExperiment is implemented by experiments
+// that limit how long their candidates run.
+// See Facts.SetTimeout and Facts.SetCandidateTimeout.
+type timeoutExperiment interface {
+	Timeout(name string) time.Duration
+}
+
+func timeout(e Experiment, name string) time.Duration {
+	if t, ok := e.(timeoutExperiment); ok && name != "__control__" {
+		return t.Timeout(name)
+	}
+	return 0
+}
+
+// strategyExperiment is implemented by experiments
+// that choose how their behaviors run.
+// See Facts.SetStrategy.
+type strategyExperiment interface {
+	Strategy() Strategy
+}
+
+func experimentStrategy(e Experiment) Strategy {
+	if s, ok := e.(strategyExperiment); ok {
+		return s.Strategy()
+	}
+	return Parallel
+}
+
+// samplerExperiment is implemented by experiments
+// that run only for a percentage of their runs.
+// See Facts.SetSampler.
+type samplerExperiment interface {
+	Sampler() *Sampler
+}
+
+func experimentSampler(e Experiment) *Sampler {
+	if s, ok := e.(samplerExperiment); ok {
+		return s.Sampler()
+	}
+	return nil
+}
+
+// breakerExperiment is implemented by experiments
+// that stop running misbehaving candidates.
+// See Facts.SetCircuitBreaker.
+type breakerExperiment interface {
+	CircuitBreaker() *CircuitBreaker
+}
+
+func circuitBreaker(e Experiment) *CircuitBreaker {
+	if b, ok := e.(breakerExperiment); ok {
+		return b.CircuitBreaker()
+	}
+	return nil
+}
+
+// normalizerExperiment is implemented by experiments
+// that normalize values before comparing them.
+// See Facts.AddNormalizer.
+type normalizerExperiment interface {
+	Normalizers() []Normalizer
+}
+
+func experimentNormalizers(e Experiment) []Normalizer {
+	if n, ok := e.(normalizerExperiment); ok {
+		return n.Normalizers()
+	}
+	return nil
+}
+
+// ignoreRulesExperiment is implemented by experiments
+// that ignore mismatches caused by known issues.
+// See Facts.AddIgnoreRule.
+type ignoreRulesExperiment interface {
+	IgnoreRules() []IgnoreRule
+}
+
+func experimentIgnoreRules(e Experiment) []IgnoreRule {
+	if r, ok := e.(ignoreRulesExperiment); ok {
+		return r.IgnoreRules()
+	}
+	return nil
+}
+
+// publishersExperiment is implemented by experiments
+// that publish their results to other publishers.
+// See Facts.AddPublisher.
+type publishersExperiment interface {
+	Publishers() []Publisher
+}
+
+func experimentPublishers(e Experiment) []Publisher {
+	if p, ok := e.(publishersExperiment); ok {
+		return p.Publishers()
+	}
+	return nil
+}
+
 // QuickExperiment is an experiment with a very basic behavior.
 // It's always enabled and it does not publishes results anywhere.
 type QuickExperiment struct {
@@ -44,8 +265,13 @@ func (e QuickExperiment) Ignore(ctx context.Context, control, candidate *Observa
 
 // Compare returns true if the result of the control behavior is the same
 // as the result of a candidate behavior.
+// It uses the comparator set with Facts.SetComparator, if any.
+// Otherwise, values are compared with Equal and errors with EqualErrors.
 func (e QuickExperiment) Compare(ctx context.Context, control, candidate *Observation) bool {
-	return control.Error == candidate.Error && control.Value == candidate.Value
+	if c := e.Comparator(); c != nil {
+		return c(ctx, control, candidate)
+	}
+	return EqualErrors(control.Error, candidate.Error) && Equal(control.Value, candidate.Value)
 }
 
 // Publish allows you to export the result of the experiment somewhere else.
@@ -1,6 +1,9 @@
 package scientist
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
 	"golang.org/x/net/context"
@@ -117,5 +120,161 @@ func TestComputeResultIgnored(t *testing.T) {
 		if g != c.ignored {
 			t.Fatalf("ignored: got %d, expected %d", g, c.ignored)
 		}
+
+		if n := r.IgnoreCounts()[IgnoreMethod]; n != c.ignored {
+			t.Fatalf("ignored by %s: got %d, expected %d", IgnoreMethod, n, c.ignored)
+		}
+	}
+}
+
+type lengthExperiment struct {
+	QuickExperiment
+}
+
+func (lengthExperiment) Compare(ctx context.Context, control, candidate *Observation) bool {
+	return control.Value.([]string)[0] == candidate.Value.([]string)[0]
+}
+
+func (lengthExperiment) Clean(value interface{}) interface{} {
+	return len(value.([]string))
+}
+
+func TestComputeResultClean(t *testing.T) {
+	e := lengthExperiment{NewQuickExperiment()}
+
+	control := &Observation{Value: []string{"1", "2"}}
+	candidates := []*Observation{
+		{Value: []string{"1"}},
+		{Value: []string{"2", "3", "4"}},
+	}
+
+	r := gatherResult(context.Background(), e, control, candidates)
+	if len(r.Mistmaches) != 1 {
+		t.Fatalf("mismatches: got %d, expected %d", len(r.Mistmaches), 1)
+	}
+
+	if control.CleanedValue != 2 {
+		t.Fatalf("control cleaned value: got %v, expected %v", control.CleanedValue, 2)
+	}
+
+	for i, w := range []int{1, 3} {
+		if g := candidates[i].CleanedValue; g != w {
+			t.Fatalf("candidate cleaned value: got %v, expected %v", g, w)
+		}
+	}
+
+	err := MismatchError{r}
+	if g := err.Error(); !strings.Contains(g, "returned 3, control returned 2") {
+		t.Fatalf("mismatch error got %q, expected cleaned values", g)
+	}
+
+	if g := err.Error(); !strings.Contains(g, "differs at [0]: changed; [1]: changed; [2]: added") {
+		t.Fatalf("mismatch error got %q, expected differences without values", g)
+	}
+
+	for _, d := range r.Mistmaches[0].Diff {
+		if d.Control != nil || d.Candidate != nil {
+			t.Fatalf("expected differences without values, got %v", d)
+		}
+	}
+}
+
+// upperExperiment cleans values like the package doc
+// does, asserting their type without checking it.
+type upperExperiment struct {
+	publishExperiment
+}
+
+func (upperExperiment) Clean(value interface{}) interface{} {
+	return strings.ToUpper(value.(string))
+}
+
+func TestRunCleanFailingCandidate(t *testing.T) {
+	c := upperExperiment{publishExperiment{NewQuickExperiment(), make(chan Result, 1)}}
+	c.Use(func(ctx context.Context) (interface{}, error) {
+		return "calavera", nil
+	})
+	c.Try("failing", func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("failure")
+	})
+	c.Try("wrong type", func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+
+	v, err := Run(c)
+	if err != nil || v != "calavera" {
+		t.Fatalf("expected the control value, got %v and %v", v, err)
+	}
+
+	r := <-c.published
+	if r.Control.CleanedValue != "CALAVERA" {
+		t.Fatalf("control cleaned value: got %v, expected %v", r.Control.CleanedValue, "CALAVERA")
+	}
+
+	for _, o := range r.Candidates {
+		if o.CleanedValue != o.Value {
+			t.Fatalf("candidate %s cleaned value: got %v, expected %v", o.Name, o.CleanedValue, o.Value)
+		}
+	}
+}
+
+func TestQuickExperimentCompareUncomparable(t *testing.T) {
+	e := NewQuickExperiment()
+	ctx := context.Background()
+
+	control := &Observation{Value: []string{"1"}, Error: uncomparableError{"a"}}
+	candidate := &Observation{Value: []string{"1"}, Error: uncomparableError{"a"}}
+
+	if !e.Compare(ctx, control, candidate) {
+		t.Fatal("expected uncomparable values to match")
+	}
+
+	candidate.Value = map[string]string{"1": "1"}
+	if e.Compare(ctx, control, candidate) {
+		t.Fatal("expected different values to not match")
+	}
+}
+
+func TestComputeResultIgnoreRules(t *testing.T) {
+	e := NewQuickExperiment()
+	e.AddIgnoreRule(
+		IgnoreRule{Name: "without function"},
+		IgnoreRule{
+			Name:   "negative",
+			Ticket: "#1",
+			Ignore: func(ctx context.Context, control, candidate *Observation) bool {
+				return candidate.Value.(int) < 0
+			},
+		},
+		IgnoreRule{
+			Name: "zero",
+			Ignore: func(ctx context.Context, control, candidate *Observation) bool {
+				return candidate.Value.(int) <= 0
+			},
+		},
+	)
+
+	control := &Observation{Value: 1}
+	candidates := []*Observation{
+		{Value: -1},
+		{Value: -2},
+		{Value: 0},
+		{Value: 2},
+		{Value: 1},
+	}
+
+	r := gatherResult(context.Background(), e, control, candidates)
+	if len(r.Mistmaches) != 1 || r.Mistmaches[0].Value != 2 {
+		t.Fatalf("mismatches: got %v, expected only 2", r.Mistmaches)
+	}
+
+	counts := r.IgnoreCounts()
+	w := map[string]int{"negative": 2, "zero": 1}
+	if !reflect.DeepEqual(counts, w) {
+		t.Fatalf("ignore counts: got %v, expected %v", counts, w)
+	}
+
+	if rule := candidates[0].IgnoredBy; rule.Name != "negative" || rule.Ticket != "#1" {
+		t.Fatalf("ignored by: got %v, expected the negative rule", rule)
 	}
 }
@@ -1,7 +1,10 @@
 package scientist
 
 import (
+	"math/bits"
 	"math/rand"
+	randv2 "math/rand/v2"
+	"sync"
 	"time"
 )
 
@@ -9,12 +12,28 @@ import (
 type Facts struct {
 	behaviors       map[string]Behavior
 	behaviorsAccess []string
+	async           bool
+	timeout         time.Duration
+	timeouts        map[string]time.Duration
+	strategy        Strategy
+	sampler         *Sampler
+	repanic         bool
+	breaker         *CircuitBreaker
+	comparator      Comparator
+	normalizers     []Normalizer
+	ignoreRules     []IgnoreRule
+	publishers      []Publisher
+
+	mu     sync.Mutex
+	source rand.Source
+	seed   *int64
 }
 
 // NewFacts create a new set of facts for a experiment.
 func NewFacts() *Facts {
 	return &Facts{
 		behaviors: make(map[string]Behavior),
+		timeouts:  make(map[string]time.Duration),
 	}
 }
 
@@ -45,18 +64,213 @@ func (f *Facts) Try(name string, behavior Behavior) error {
 }
 
 // Shuffle randomizes the behavior access.
+// It returns a new slice every time, using the next seed.
 func (f *Facts) Shuffle() []string {
-	t := time.Now()
-	rand.Seed(int64(t.Nanosecond()))
+	return f.ShuffleWithSeed(f.NextSeed())
+}
 
-	arr := f.behaviorsAccess
+// ShuffleWithSeed randomizes the behavior access with a given seed.
+// The same seed always returns the same order. It's an unbiased
+// shuffle with a PCG generator, which is cheap to seed on every run.
+func (f *Facts) ShuffleWithSeed(seed int64) []string {
+	var pcg randv2.PCG
+	pcg.Seed(uint64(seed), 0)
+
+	arr := append([]string(nil), f.behaviorsAccess...)
 	for i := len(arr) - 1; i > 0; i-- {
-		j := rand.Intn(i)
+		j := boundedRand(&pcg, uint64(i+1))
 		arr[i], arr[j] = arr[j], arr[i]
 	}
 	return arr
 }
 
+// boundedRand returns an unbiased random number in [0, n), using
+// Lemire's multiply and reject method. Unlike randv2.New(pcg).IntN,
+// it doesn't move the generator to the heap.
+func boundedRand(pcg *randv2.PCG, n uint64) uint64 {
+	hi, lo := bits.Mul64(pcg.Uint64(), n)
+	if lo < n {
+		threshold := -n % n
+		for lo < threshold {
+			hi, lo = bits.Mul64(pcg.Uint64(), n)
+		}
+	}
+	return hi
+}
+
+// NextSeed returns the seed for the next shuffle.
+// Seeds come from the random source set with SetRandSource,
+// or from math/rand if the experiment doesn't have one.
+func (f *Facts) NextSeed() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case f.seed != nil:
+		return *f.seed
+	case f.source != nil:
+		return f.source.Int63()
+	default:
+		return rand.Int63()
+	}
+}
+
+// SetRandSource sets the random source that generates the seeds of the shuffles.
+func (f *Facts) SetRandSource(source rand.Source) {
+	f.mu.Lock()
+	f.source = source
+	f.mu.Unlock()
+}
+
+// SetSeed sets the seed for all the shuffles, so
+// the behaviors always run in the same order.
+// Use it with the seed recorded in a Result to replay a run.
+func (f *Facts) SetSeed(seed int64) {
+	f.mu.Lock()
+	f.seed = &seed
+	f.mu.Unlock()
+}
+
+// Async returns true if the experiment returns the control
+// result without waiting for the candidates to finish.
+func (f *Facts) Async() bool {
+	return f.async
+}
+
+// SetAsync sets whether the experiment returns the control
+// result without waiting for the candidates to finish.
+// Results are gathered and published in the background
+// once all the candidates finish. Experiments with sequential
+// strategies still wait for all their behaviors to finish.
+func (f *Facts) SetAsync(async bool) {
+	f.async = async
+}
+
+// Timeout returns how long a candidate can run before its
+// context is canceled and its observation is recorded as timed out.
+// Zero means that the candidate can run forever.
+func (f *Facts) Timeout(name string) time.Duration {
+	if t, ok := f.timeouts[name]; ok {
+		return t
+	}
+	return f.timeout
+}
+
+// SetTimeout sets how long every candidate can run.
+// Use SetCandidateTimeout to set it for a specific candidate.
+// With sequential strategies, the next behavior doesn't start until
+// a timed out candidate returns, so candidates should stop when
+// their context is canceled.
+func (f *Facts) SetTimeout(timeout time.Duration) {
+	f.timeout = timeout
+}
+
+// SetCandidateTimeout sets how long a specific candidate can run.
+// It takes precedence over the timeout set with SetTimeout.
+func (f *Facts) SetCandidateTimeout(name string, timeout time.Duration) {
+	f.timeouts[name] = timeout
+}
+
+// Strategy returns how the experiment runs its behaviors.
+func (f *Facts) Strategy() Strategy {
+	return f.strategy
+}
+
+// SetStrategy sets how the experiment runs its behaviors.
+// Use a sequential strategy for behaviors that
+// are not safe to run concurrently. Sequential behaviors
+// always finish before Run returns, even when the
+// experiment is asynchronous or the control panics.
+func (f *Facts) SetStrategy(strategy Strategy) {
+	f.strategy = strategy
+}
+
+// Sampler returns the sampler that decides
+// when the experiment is enabled, if any.
+func (f *Facts) Sampler() *Sampler {
+	return f.sampler
+}
+
+// SetSampler sets the sampler that decides when the experiment is enabled.
+// The experiment runs only when both IsEnabled and the sampler enable it.
+func (f *Facts) SetSampler(sampler *Sampler) {
+	f.sampler = sampler
+}
+
+// RepanicControl returns true if the experiment panics
+// again when the control behavior panics.
+func (f *Facts) RepanicControl() bool {
+	return f.repanic
+}
+
+// SetRepanicControl sets whether the experiment panics again, on the
+// goroutine that runs the experiment, when the control behavior panics.
+// Otherwise, the panic is returned as a *PanicError. Experiments with
+// sequential strategies panic once all their behaviors finish.
+func (f *Facts) SetRepanicControl(repanic bool) {
+	f.repanic = repanic
+}
+
+// CircuitBreaker returns the circuit breaker that
+// stops running misbehaving candidates, if any.
+func (f *Facts) CircuitBreaker() *CircuitBreaker {
+	return f.breaker
+}
+
+// SetCircuitBreaker sets the circuit breaker that
+// stops running misbehaving candidates.
+func (f *Facts) SetCircuitBreaker(breaker *CircuitBreaker) {
+	f.breaker = breaker
+}
+
+// Comparator returns the function that QuickExperiment
+// uses to compare observations, if any.
+func (f *Facts) Comparator() Comparator {
+	return f.comparator
+}
+
+// SetComparator sets the function that QuickExperiment uses to
+// compare observations, instead of overriding its Compare method.
+// See the compare package for ready-made comparators.
+func (f *Facts) SetComparator(comparator Comparator) {
+	f.comparator = comparator
+}
+
+// Normalizers returns the normalizers that transform
+// the values before comparing them, in order.
+func (f *Facts) Normalizers() []Normalizer {
+	return f.normalizers
+}
+
+// AddNormalizer adds normalizers that transform the values before
+// comparing them. Normalizers run in the order they're added.
+func (f *Facts) AddNormalizer(normalizers ...Normalizer) {
+	f.normalizers = append(f.normalizers, normalizers...)
+}
+
+// IgnoreRules returns the rules that ignore mismatches, in order.
+func (f *Facts) IgnoreRules() []IgnoreRule {
+	return f.ignoreRules
+}
+
+// AddIgnoreRule adds rules that ignore mismatches caused by known issues.
+// Rules are checked in the order they're added, after the
+// experiment's Ignore method.
+func (f *Facts) AddIgnoreRule(rules ...IgnoreRule) {
+	f.ignoreRules = append(f.ignoreRules, rules...)
+}
+
+// Publishers returns the publishers that publish the experiment's results.
+func (f *Facts) Publishers() []Publisher {
+	return f.publishers
+}
+
+// AddPublisher adds publishers that publish the experiment's
+// results, after the experiment's Publish method.
+func (f *Facts) AddPublisher(publishers ...Publisher) {
+	f.publishers = append(f.publishers, publishers...)
+}
+
 func (f *Facts) tryBehavior(name string, behavior Behavior) error {
 	if _, exist := f.behaviors[name]; exist {
 		return behaviorAlreadyExist{name}
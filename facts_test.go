@@ -1,6 +1,10 @@
 package scientist
 
 import (
+	"math/rand"
+	randv2 "math/rand/v2"
+	"reflect"
+	"strings"
 	"testing"
 
 	"golang.org/x/net/context"
@@ -49,3 +53,82 @@ func TestBehavior(t *testing.T) {
 		t.Fatal("expected duplicated behavior error, got nil")
 	}
 }
+
+func TestShuffleWithSeed(t *testing.T) {
+	f := NewFacts()
+	f.Use(emptyBehavior)
+	f.Try("a", emptyBehavior)
+	f.Try("b", emptyBehavior)
+
+	access := append([]string(nil), f.behaviorsAccess...)
+
+	orders := make(map[string]bool)
+	for seed := int64(0); seed < 100; seed++ {
+		o := f.ShuffleWithSeed(seed)
+		if again := f.ShuffleWithSeed(seed); !reflect.DeepEqual(o, again) {
+			t.Fatalf("seed %d: got %v, expected %v", seed, again, o)
+		}
+		orders[strings.Join(o, ",")] = true
+	}
+
+	if len(orders) != 6 {
+		t.Fatalf("got %d different orders, expected %d: %v", len(orders), 6, orders)
+	}
+
+	if !reflect.DeepEqual(access, f.behaviorsAccess) {
+		t.Fatalf("behaviors access changed: got %v, expected %v", f.behaviorsAccess, access)
+	}
+}
+
+func TestShuffleWithSeedAllocations(t *testing.T) {
+	f := NewFacts()
+	f.Use(emptyBehavior)
+	f.Try("a", emptyBehavior)
+	f.Try("b", emptyBehavior)
+
+	seed := int64(0)
+	allocs := testing.AllocsPerRun(100, func() {
+		seed++
+		f.ShuffleWithSeed(seed)
+	})
+	if allocs > 1 {
+		t.Fatalf("got %v allocations, expected only the shuffled slice", allocs)
+	}
+}
+
+func TestBoundedRand(t *testing.T) {
+	var pcg randv2.PCG
+	pcg.Seed(1, 2)
+
+	counts := make([]int, 6)
+	for i := 0; i < 60000; i++ {
+		n := boundedRand(&pcg, uint64(len(counts)))
+		if n >= uint64(len(counts)) {
+			t.Fatalf("got %d, expected less than %d", n, len(counts))
+		}
+		counts[n]++
+	}
+
+	for n, c := range counts {
+		if c < 9000 || c > 11000 {
+			t.Fatalf("got %d draws of %d, expected around 10000: %v", c, n, counts)
+		}
+	}
+}
+
+func TestSetSeed(t *testing.T) {
+	f := NewFacts()
+	f.SetRandSource(rand.NewSource(42))
+
+	first := f.NextSeed()
+	if first != rand.NewSource(42).Int63() {
+		t.Fatalf("got seed %d, expected the first value of the random source", first)
+	}
+
+	f.SetSeed(first)
+	for i := 0; i < 3; i++ {
+		if s := f.NextSeed(); s != first {
+			t.Fatalf("got seed %d, expected %d", s, first)
+		}
+	}
+}
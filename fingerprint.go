@@ -0,0 +1,88 @@
+package scientist
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// keyPattern matches slice indexes and map keys in difference paths,
+// including quoted string keys that contain brackets.
+var keyPattern = regexp.MustCompile(`\[(?:"(?:[^"\\]|\\.)*"|[^\]]*)\]`)
+
+// Fingerprint returns a stable identifier for a mismatch between
+// the control and a candidate. It's derived from the shape of the
+// candidate's differences, their paths and kinds without values, and
+// from the types of the errors returned by both behaviors, so the same
+// bug produces the same fingerprint. Slice indexes and map keys in paths
+// are ignored, so mismatches in different elements or keys, like user IDs,
+// share a fingerprint.
+func Fingerprint(control, candidate *Observation) string {
+	seen := make(map[string]bool)
+	var shape []string
+	for _, d := range candidate.Diff {
+		s := fmt.Sprintf("%s %s", keyPattern.ReplaceAllString(d.Path, "[*]"), d.Kind)
+		if !seen[s] {
+			seen[s] = true
+			shape = append(shape, s)
+		}
+	}
+	sort.Strings(shape)
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\n%T\n%T", strings.Join(shape, "\n"), control.Error, candidate.Error)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Aggregator groups the mismatches of experiments by their fingerprints.
+// Use it in publishers to count mismatches and report only new ones.
+type Aggregator struct {
+	mu     sync.Mutex
+	counts map[string]map[string]int
+}
+
+// NewAggregator creates a new empty Aggregator.
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		counts: make(map[string]map[string]int),
+	}
+}
+
+// Add counts the mismatches of a result by their fingerprints.
+// It returns the mismatches whose fingerprints it has not
+// seen before for the result's experiment.
+func (a *Aggregator) Add(result Result) []*Observation {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	counts, ok := a.counts[result.name]
+	if !ok {
+		counts = make(map[string]int)
+		a.counts[result.name] = counts
+	}
+
+	var found []*Observation
+	for _, o := range result.Mistmaches {
+		if counts[o.Fingerprint] == 0 {
+			found = append(found, o)
+		}
+		counts[o.Fingerprint]++
+	}
+	return found
+}
+
+// Counts returns the number of mismatches of
+// an experiment by their fingerprints.
+func (a *Aggregator) Counts(experiment string) map[string]int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	counts := make(map[string]int, len(a.counts[experiment]))
+	for f, n := range a.counts[experiment] {
+		counts[f] = n
+	}
+	return counts
+}
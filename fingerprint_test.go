@@ -0,0 +1,87 @@
+package scientist
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestFingerprint(t *testing.T) {
+	control := &Observation{Value: order{Items: []item{{"a", 1, nil}, {"b", 2, nil}}}}
+
+	price := func(i int, p float64) *Observation {
+		v := order{Items: []item{{"a", 1, nil}, {"b", 2, nil}}}
+		v.Items[i].Price = p
+		o := &Observation{Value: v}
+		o.Diff = Diff(control.Value, o.Value)
+		return o
+	}
+
+	a := Fingerprint(control, price(0, 10))
+	b := Fingerprint(control, price(1, 20))
+	if a != b {
+		t.Fatalf("expected same shape to have the same fingerprint, got %s and %s", a, b)
+	}
+
+	name := &Observation{Value: order{ID: 1, Items: control.Value.(order).Items}}
+	name.Diff = Diff(control.Value, name.Value)
+	if c := Fingerprint(control, name); c == a {
+		t.Fatalf("expected different shapes to have different fingerprints, got %s", c)
+	}
+
+	users := func(id string) *Observation {
+		o := &Observation{Value: map[string]int{id: 2}}
+		o.Diff = Diff(map[string]int{id: 1}, o.Value)
+		return o
+	}
+
+	c := Fingerprint(control, users("user-123"))
+	d := Fingerprint(control, users(`user-["456"]`))
+	if c != d {
+		t.Fatalf("expected changes in different keys to have the same fingerprint, got %s and %s", c, d)
+	}
+
+	failed := price(0, 10)
+	failed.Error = errors.New("fail")
+	if c := Fingerprint(control, failed); c == a {
+		t.Fatalf("expected different errors to have different fingerprints, got %s", c)
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	e := NewQuickExperiment()
+	a := NewAggregator()
+
+	control := &Observation{Value: 1}
+	run := func(values ...int) Result {
+		var candidates []*Observation
+		for _, v := range values {
+			candidates = append(candidates, &Observation{Value: v})
+		}
+		return gatherResult(context.Background(), e, control, candidates)
+	}
+
+	if found := a.Add(run(2, 3)); len(found) != 1 {
+		t.Fatalf("new mismatches: got %d, expected %d", len(found), 1)
+	}
+
+	if found := a.Add(run(4)); len(found) != 0 {
+		t.Fatalf("new mismatches: got %d, expected %d", len(found), 0)
+	}
+
+	if found := a.Add(run(-1, 1)); len(found) != 0 {
+		t.Fatalf("new mismatches: got %d, expected %d", len(found), 0)
+	}
+
+	counts := a.Counts(e.Name())
+	if len(counts) != 1 {
+		t.Fatalf("fingerprints: got %d, expected %d", len(counts), 1)
+	}
+
+	for _, n := range counts {
+		if n != 4 {
+			t.Fatalf("count: got %d, expected %d", n, 4)
+		}
+	}
+}
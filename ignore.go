@@ -0,0 +1,37 @@
+package scientist
+
+import "golang.org/x/net/context"
+
+// IgnoreMethod is the name of the rule recorded in observations
+// ignored by the experiment's Ignore method.
+const IgnoreMethod = "Ignore"
+
+// IgnoreRule is a named rule to ignore mismatches caused by known issues.
+// See Facts.AddIgnoreRule.
+type IgnoreRule struct {
+	// Name identifies the rule in the results.
+	Name string
+	// Ticket is a reference to the known issue, like an issue URL.
+	Ticket string
+	// Description explains why the mismatches are ignored.
+	Description string
+	// Ignore returns true if a mismatched candidate can be ignored.
+	// Rules without it never ignore mismatches.
+	Ignore func(ctx context.Context, control, candidate *Observation) bool
+}
+
+// ignoredBy returns the rule that ignores a mismatched candidate, if any.
+// The experiment's Ignore method is checked before the rules, in order.
+func ignoredBy(ctx context.Context, e Experiment, control, candidate *Observation) *IgnoreRule {
+	if e.Ignore(ctx, control, candidate) {
+		return &IgnoreRule{Name: IgnoreMethod}
+	}
+
+	for _, rule := range experimentIgnoreRules(e) {
+		if rule.Ignore != nil && rule.Ignore(ctx, control, candidate) {
+			r := rule
+			return &r
+		}
+	}
+	return nil
+}
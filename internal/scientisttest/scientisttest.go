@@ -0,0 +1,65 @@
+// Package scientisttest builds the results of real experiment
+// runs for the tests of the publishers.
+package scientisttest
+
+import (
+	"testing"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// Candidate is a candidate behavior that returns Value and Error.
+type Candidate struct {
+	Name  string
+	Value interface{}
+	Error error
+}
+
+// experiment is a QuickExperiment with a name
+// that keeps the result it publishes.
+type experiment struct {
+	scientist.QuickExperiment
+	name    string
+	results chan scientist.Result
+}
+
+func (e experiment) Name() string {
+	return e.name
+}
+
+func (e experiment) Publish(ctx context.Context, result scientist.Result) error {
+	e.results <- result
+	return nil
+}
+
+// Result runs an experiment with a name, a control behavior that returns
+// control and the candidates, and returns the result it publishes.
+// It fails the test if the experiment doesn't publish a result.
+func Result(t testing.TB, name string, control interface{}, candidates ...Candidate) scientist.Result {
+	t.Helper()
+
+	e := experiment{scientist.NewQuickExperiment(), name, make(chan scientist.Result, 1)}
+	e.Use(func(ctx context.Context) (interface{}, error) {
+		return control, nil
+	})
+	for _, c := range candidates {
+		c := c
+		e.Try(c.Name, func(ctx context.Context) (interface{}, error) {
+			return c.Value, c.Error
+		})
+	}
+
+	if _, err := scientist.Run(e); err != nil {
+		t.Fatal(err)
+	}
+
+	// results are published before Run returns.
+	select {
+	case result := <-e.results:
+		return result
+	default:
+		t.Fatalf("experiment %s didn't publish a result", name)
+		return scientist.Result{}
+	}
+}
@@ -0,0 +1,338 @@
+package scientist
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JSONSchemaVersion is the version of the JSON representation
+// of results and observations. It changes when fields are
+// removed or change their meaning, not when they're added.
+//
+// Results are encoded as:
+//
+//	{
+//	  "schema_version": 1,
+//	  "experiment": "my experiment",
+//	  "matches": false,
+//	  "control": {observation},
+//	  "candidates": [{observation}],
+//	  "mismatches": ["candidate name"],
+//	  "ignored": [],
+//	  "timed_out": [],
+//	  "strategy": "parallel",
+//	  "order": ["__control__", "candidate name"],
+//	  "seed": "1234",
+//	  "sampling": {"percent": 10, "key": "42", "keyed": true, "bucket": 3.5, "sampled": true},
+//	  "tripped": [],
+//	  "skipped": []
+//	}
+//
+// And observations as:
+//
+//	{
+//	  "name": "candidate name",
+//	  "start": "2016-01-02T15:04:05.999999999Z",
+//	  "duration": "1.5ms",
+//	  "value": {"id": 1},
+//	  "error": {"message": "oh no!", "type": "*scientist.PanicError", "panic": {"value": "oh no!", "stack": "..."}},
+//	  "timed_out": false,
+//	  "ignored_by": {"name": "rule", "ticket": "BUG-1", "description": "..."},
+//	  "diff": [{"path": ".id", "kind": "changed", "control": 1, "candidate": 2}],
+//	  "fingerprint": "..."
+//	}
+//
+// Values are the cleaned values, see Observation.CleanedValue.
+// Values that can't be encoded as JSON are encoded as their
+// string representation. The seed is a string to keep its precision.
+const JSONSchemaVersion = 1
+
+// JSONError is the error of an observation decoded from
+// JSON, with the message and the type of the original error.
+// Panics are decoded as *PanicError instead.
+type JSONError struct {
+	Message string
+	Type    string
+}
+
+func (e *JSONError) Error() string {
+	return e.Message
+}
+
+type jsonResult struct {
+	SchemaVersion int            `json:"schema_version"`
+	Experiment    string         `json:"experiment"`
+	Matches       bool           `json:"matches"`
+	Control       *Observation   `json:"control"`
+	Candidates    []*Observation `json:"candidates"`
+	Mismatches    []string       `json:"mismatches"`
+	Ignored       []string       `json:"ignored"`
+	TimedOut      []string       `json:"timed_out"`
+	Strategy      Strategy       `json:"strategy"`
+	Order         []string       `json:"order"`
+	Seed          int64          `json:"seed,string"`
+	Sampling      *jsonSampling  `json:"sampling,omitempty"`
+	Tripped       []string       `json:"tripped,omitempty"`
+	Skipped       []string       `json:"skipped,omitempty"`
+}
+
+type jsonSampling struct {
+	Percent float64 `json:"percent"`
+	Key     string  `json:"key,omitempty"`
+	Keyed   bool    `json:"keyed"`
+	Bucket  float64 `json:"bucket"`
+	Sampled bool    `json:"sampled"`
+}
+
+type jsonObservation struct {
+	Name        string           `json:"name"`
+	Start       time.Time        `json:"start"`
+	Duration    string           `json:"duration"`
+	Value       json.RawMessage  `json:"value"`
+	Error       *jsonError       `json:"error,omitempty"`
+	TimedOut    bool             `json:"timed_out"`
+	IgnoredBy   *jsonIgnoreRule  `json:"ignored_by,omitempty"`
+	Diff        []jsonDifference `json:"diff,omitempty"`
+	Fingerprint string           `json:"fingerprint,omitempty"`
+}
+
+type jsonError struct {
+	Message string     `json:"message"`
+	Type    string     `json:"type"`
+	Panic   *jsonPanic `json:"panic,omitempty"`
+}
+
+type jsonPanic struct {
+	Value string `json:"value"`
+	Stack string `json:"stack"`
+}
+
+type jsonIgnoreRule struct {
+	Name        string `json:"name"`
+	Ticket      string `json:"ticket,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type jsonDifference struct {
+	Path      string          `json:"path"`
+	Kind      DiffKind        `json:"kind"`
+	Control   json.RawMessage `json:"control,omitempty"`
+	Candidate json.RawMessage `json:"candidate,omitempty"`
+}
+
+// MarshalJSON encodes the result as JSON, see JSONSchemaVersion.
+// Mismatched, ignored and timed out candidates are encoded by name.
+func (r Result) MarshalJSON() ([]byte, error) {
+	j := jsonResult{
+		SchemaVersion: JSONSchemaVersion,
+		Experiment:    r.name,
+		Matches:       r.Matches(),
+		Control:       r.Control,
+		Candidates:    r.Candidates,
+		Mismatches:    names(r.Mistmaches),
+		Ignored:       names(r.Ignored),
+		TimedOut:      names(r.TimedOut),
+		Strategy:      r.Strategy,
+		Order:         r.Order,
+		Seed:          r.Seed,
+		Tripped:       r.Tripped,
+		Skipped:       r.Skipped,
+	}
+
+	if s := r.Sampling; s != nil {
+		j.Sampling = &jsonSampling{s.Percent, s.Key, s.Keyed, s.Bucket, s.Sampled}
+	}
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a result encoded with MarshalJSON.
+// Mismatched, ignored and timed out candidates point to the candidates.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var j jsonResult
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	if j.SchemaVersion != JSONSchemaVersion {
+		return fmt.Errorf("unsupported result schema version: %d", j.SchemaVersion)
+	}
+
+	candidates := make(map[string]*Observation, len(j.Candidates))
+	for _, c := range j.Candidates {
+		candidates[c.Name] = c
+	}
+
+	lookup := func(names []string) ([]*Observation, error) {
+		var os []*Observation
+		for _, name := range names {
+			o, ok := candidates[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown candidate in result: %s", name)
+			}
+			os = append(os, o)
+		}
+		return os, nil
+	}
+
+	*r = Result{
+		name:       j.Experiment,
+		Control:    j.Control,
+		Candidates: j.Candidates,
+		Strategy:   j.Strategy,
+		Order:      j.Order,
+		Seed:       j.Seed,
+		Tripped:    j.Tripped,
+		Skipped:    j.Skipped,
+	}
+
+	var err error
+	if r.Mistmaches, err = lookup(j.Mismatches); err != nil {
+		return err
+	}
+	if r.Ignored, err = lookup(j.Ignored); err != nil {
+		return err
+	}
+	if r.TimedOut, err = lookup(j.TimedOut); err != nil {
+		return err
+	}
+
+	if s := j.Sampling; s != nil {
+		r.Sampling = &Sampling{s.Percent, s.Key, s.Keyed, s.Bucket, s.Sampled}
+	}
+
+	return nil
+}
+
+func names(os []*Observation) []string {
+	names := make([]string, len(os))
+	for i, o := range os {
+		names[i] = o.Name
+	}
+	return names
+}
+
+// MarshalJSON encodes the observation as JSON, see JSONSchemaVersion.
+func (o Observation) MarshalJSON() ([]byte, error) {
+	j := jsonObservation{
+		Name:        o.Name,
+		Start:       o.Start,
+		Duration:    o.Duration.String(),
+		Value:       jsonValue(o.CleanedValue),
+		TimedOut:    o.TimedOut,
+		Fingerprint: o.Fingerprint,
+	}
+
+	if o.Error != nil {
+		j.Error = &jsonError{
+			Message: o.Error.Error(),
+			Type:    fmt.Sprintf("%T", o.Error),
+		}
+		if p, ok := o.Error.(*PanicError); ok {
+			j.Error.Panic = &jsonPanic{
+				Value: fmt.Sprint(p.Value),
+				Stack: string(p.Stack),
+			}
+		}
+	}
+
+	if r := o.IgnoredBy; r != nil {
+		j.IgnoredBy = &jsonIgnoreRule{r.Name, r.Ticket, r.Description}
+	}
+
+	for _, d := range o.Diff {
+		j.Diff = append(j.Diff, jsonDifference{
+			Path:      d.Path,
+			Kind:      d.Kind,
+			Control:   jsonValue(d.Control),
+			Candidate: jsonValue(d.Candidate),
+		})
+	}
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes an observation encoded with MarshalJSON.
+// Values are decoded like json.Unmarshal does into an interface{},
+// in both Value and CleanedValue. Errors are decoded as *JSONError,
+// or *PanicError, with the panic value as a string.
+// Ignore rules are decoded without their Ignore function.
+func (o *Observation) UnmarshalJSON(data []byte) error {
+	var j jsonObservation
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(j.Duration)
+	if err != nil {
+		return err
+	}
+
+	value, err := decodeValue(j.Value)
+	if err != nil {
+		return err
+	}
+
+	*o = Observation{
+		Name:         j.Name,
+		Start:        j.Start,
+		Duration:     duration,
+		Value:        value,
+		CleanedValue: value,
+		TimedOut:     j.TimedOut,
+		Fingerprint:  j.Fingerprint,
+	}
+
+	if e := j.Error; e != nil {
+		if e.Panic != nil && e.Type == fmt.Sprintf("%T", &PanicError{}) {
+			o.Error = &PanicError{Name: j.Name, Value: e.Panic.Value, Stack: []byte(e.Panic.Stack)}
+		} else {
+			o.Error = &JSONError{Message: e.Message, Type: e.Type}
+		}
+	}
+
+	if r := j.IgnoredBy; r != nil {
+		o.IgnoredBy = &IgnoreRule{Name: r.Name, Ticket: r.Ticket, Description: r.Description}
+	}
+
+	for _, d := range j.Diff {
+		control, err := decodeValue(d.Control)
+		if err != nil {
+			return err
+		}
+
+		candidate, err := decodeValue(d.Candidate)
+		if err != nil {
+			return err
+		}
+
+		o.Diff = append(o.Diff, Difference{Path: d.Path, Kind: d.Kind, Control: control, Candidate: candidate})
+	}
+
+	return nil
+}
+
+// jsonValue encodes a value as JSON, or its
+// string representation if it can't be encoded.
+func jsonValue(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return b
+}
+
+func decodeValue(data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
@@ -0,0 +1,122 @@
+package scientist
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestResultJSON(t *testing.T) {
+	e := NewQuickExperiment()
+	e.AddIgnoreRule(IgnoreRule{
+		Name:   "negative",
+		Ticket: "BUG-1",
+		Ignore: func(ctx context.Context, control, candidate *Observation) bool {
+			v, _ := candidate.Value.(map[string]int)
+			return v["id"] < 0
+		},
+	})
+
+	start := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+	control := &Observation{Name: "__control__", Start: start, Duration: 1500 * time.Microsecond, Value: map[string]int{"id": 1}}
+	candidates := []*Observation{
+		{Name: "mismatch", Value: map[string]int{"id": 2}},
+		{Name: "ignored", Value: map[string]int{"id": -1}},
+		{Name: "panic", Error: &PanicError{Name: "panic", Value: "oh no!", Stack: []byte("stack")}},
+		{Name: "failure", Value: map[string]int{"id": 1}, Error: errors.New("failure")},
+	}
+
+	result := gatherResult(context.Background(), e, control, candidates)
+	result.Seed = 1<<62 + 1
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"schema_version": float64(JSONSchemaVersion),
+		"experiment":     "experiment",
+		"matches":        false,
+		"strategy":       "parallel",
+		"seed":           "4611686018427387905",
+	}
+	for k, v := range expected {
+		if raw[k] != v {
+			t.Fatalf("%s: got %v, expected %v", k, raw[k], v)
+		}
+	}
+
+	c := raw["control"].(map[string]interface{})
+	if c["duration"] != "1.5ms" || c["start"] != "2016-01-02T15:04:05Z" {
+		t.Fatalf("expected durations and times with units, got %v", c)
+	}
+
+	var decoded Result
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Name() != "experiment" || decoded.Seed != result.Seed || decoded.Control.Duration != control.Duration || !decoded.Control.Start.Equal(start) {
+		t.Fatalf("expected result to be decoded, got %+v", decoded)
+	}
+
+	if len(decoded.Mistmaches) != 3 || decoded.Mistmaches[0] != decoded.Candidates[0] {
+		t.Fatalf("expected mismatches to point to the candidates, got %v", decoded.Mistmaches)
+	}
+
+	if d := decoded.Candidates[0].Diff; len(d) != 1 || d[0].Path != `["id"]` || d[0].Kind != Changed || d[0].Candidate != float64(2) {
+		t.Fatalf("expected diff to be decoded, got %v", d)
+	}
+
+	if r := decoded.Ignored[0].IgnoredBy; r == nil || r.Name != "negative" || r.Ticket != "BUG-1" {
+		t.Fatalf("expected ignore rule to be decoded, got %v", r)
+	}
+
+	var p *PanicError
+	if !errors.As(decoded.Candidates[2].Error, &p) || p.Value != "oh no!" || string(p.Stack) != "stack" {
+		t.Fatalf("expected panic to be decoded, got %v", decoded.Candidates[2].Error)
+	}
+
+	var f *JSONError
+	if !errors.As(decoded.Candidates[3].Error, &f) || f.Message != "failure" || f.Type != "*errors.errorString" {
+		t.Fatalf("expected error to be decoded, got %v", decoded.Candidates[3].Error)
+	}
+
+	if decoded.Candidates[0].Fingerprint != result.Candidates[0].Fingerprint {
+		t.Fatalf("expected fingerprint to be decoded")
+	}
+}
+
+func TestObservationJSONUnsupportedValue(t *testing.T) {
+	o := Observation{Name: "candidate", CleanedValue: make(chan int)}
+
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Observation
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := decoded.Value.(string); !ok {
+		t.Fatalf("expected value to be encoded as a string, got %v", decoded.Value)
+	}
+}
+
+func TestResultJSONSchemaVersion(t *testing.T) {
+	var r Result
+	if err := json.Unmarshal([]byte(`{"schema_version": 2}`), &r); err == nil {
+		t.Fatal("expected unsupported schema version to fail")
+	}
+}
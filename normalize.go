@@ -0,0 +1,190 @@
+package scientist
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// Normalizer is the type of function that transforms the values returned
+// by the behaviors before comparing them, to remove noise like generated
+// IDs or ordering. Normalizers must not modify the values they receive,
+// and in typed experiments they must return values of the same type.
+// See Facts.AddNormalizer.
+type Normalizer func(value interface{}) interface{}
+
+// SortSlices returns a normalizer that sorts every slice inside
+// a value by the string representation of its elements.
+func SortSlices() Normalizer {
+	return transformer(func(v reflect.Value) reflect.Value {
+		if v.Kind() != reflect.Slice || v.IsNil() {
+			return v
+		}
+
+		keys := make([]string, v.Len())
+		for i := range keys {
+			keys[i] = fmt.Sprintf("%#v", valueOf(v.Index(i)))
+		}
+
+		sort.Sort(byKey{v, keys})
+		return v
+	})
+}
+
+// byKey sorts a slice by the keys of its elements.
+type byKey struct {
+	slice reflect.Value
+	keys  []string
+}
+
+func (s byKey) Len() int           { return len(s.keys) }
+func (s byKey) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s byKey) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	a, b := s.slice.Index(i), s.slice.Index(j)
+	tmp := reflect.New(a.Type()).Elem()
+	tmp.Set(a)
+	a.Set(b)
+	b.Set(tmp)
+}
+
+// ReplaceStrings returns a normalizer that replaces the
+// matches of a regular expression in every string inside a value.
+// See regexp.Regexp.ReplaceAllString.
+func ReplaceStrings(re *regexp.Regexp, replacement string) Normalizer {
+	return transformer(func(v reflect.Value) reflect.Value {
+		if v.Kind() != reflect.String {
+			return v
+		}
+
+		s := reflect.New(v.Type()).Elem()
+		s.SetString(re.ReplaceAllString(v.String(), replacement))
+		return s
+	})
+}
+
+// RoundFloats returns a normalizer that rounds every
+// float inside a value to the given number of decimal places.
+func RoundFloats(places int) Normalizer {
+	scale := math.Pow(10, float64(places))
+
+	return transformer(func(v reflect.Value) reflect.Value {
+		if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+			return v
+		}
+
+		f := reflect.New(v.Type()).Elem()
+		f.SetFloat(math.Round(v.Float()*scale) / scale)
+		return f
+	})
+}
+
+// NilAsEmpty returns a normalizer that replaces every nil slice and map
+// inside a value with an empty one, so they're equal to empty ones.
+func NilAsEmpty() Normalizer {
+	return transformer(func(v reflect.Value) reflect.Value {
+		if (v.Kind() != reflect.Slice && v.Kind() != reflect.Map) || !v.IsNil() {
+			return v
+		}
+
+		if v.Kind() == reflect.Map {
+			return reflect.MakeMap(v.Type())
+		}
+		return reflect.MakeSlice(v.Type(), 0, 0)
+	})
+}
+
+// normalize applies the normalizers to a value, in order.
+func normalize(normalizers []Normalizer, value interface{}) interface{} {
+	for _, n := range normalizers {
+		value = n(value)
+	}
+	return value
+}
+
+// transformer returns a normalizer that copies a value, applying a function
+// to every value inside it after applying it to the values inside them.
+// Unexported struct fields are copied without applying the function.
+func transformer(fn func(reflect.Value) reflect.Value) Normalizer {
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+
+		t := &transform{fn: fn, copies: make(map[pointer]reflect.Value)}
+		return t.copy(reflect.ValueOf(value)).Interface()
+	}
+}
+
+type transform struct {
+	fn     func(reflect.Value) reflect.Value
+	copies map[pointer]reflect.Value
+}
+
+// pointer identifies a copied pointer. The type is part of it because
+// a pointer to a struct and a pointer to its first field share the address.
+type pointer struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+func (t *transform) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		p := pointer{v.Pointer(), v.Type()}
+		if c, ok := t.copies[p]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		t.copies[p] = c
+		c.Elem().Set(t.copy(v.Elem()))
+		return t.fn(c)
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(t.copy(v.Elem()))
+		return t.fn(c)
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(t.copy(v.Field(i)))
+			}
+		}
+		return t.fn(c)
+	case reflect.Slice:
+		if v.IsNil() {
+			return t.fn(v)
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(t.copy(v.Index(i)))
+		}
+		return t.fn(c)
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(t.copy(v.Index(i)))
+		}
+		return t.fn(c)
+	case reflect.Map:
+		if v.IsNil() {
+			return t.fn(v)
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			c.SetMapIndex(k, t.copy(v.MapIndex(k)))
+		}
+		return t.fn(c)
+	default:
+		return t.fn(v)
+	}
+}
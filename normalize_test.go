@@ -0,0 +1,110 @@
+package scientist
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+type receipt struct {
+	ID     string
+	Lines  []string
+	Totals map[string]float64
+	Next   *receipt
+	secret string
+}
+
+func TestNormalizers(t *testing.T) {
+	value := receipt{
+		ID:     "id-123",
+		Lines:  []string{"id-9 b", "id-8 a"},
+		Totals: map[string]float64{"total": 1.2345},
+		Next:   &receipt{ID: "id-456"},
+		secret: "id-789",
+	}
+
+	normalizers := []Normalizer{
+		ReplaceStrings(regexp.MustCompile(`id-\d+`), "<id>"),
+		SortSlices(),
+		RoundFloats(2),
+	}
+
+	g := normalize(normalizers, value)
+	w := receipt{
+		ID:     "<id>",
+		Lines:  []string{"<id> a", "<id> b"},
+		Totals: map[string]float64{"total": 1.23},
+		Next:   &receipt{ID: "<id>"},
+		secret: "id-789",
+	}
+
+	if !reflect.DeepEqual(g, w) {
+		t.Fatalf("normalize got %#v, expected %#v", g, w)
+	}
+
+	if value.ID != "id-123" || value.Lines[0] != "id-9 b" || value.Next.ID != "id-456" {
+		t.Fatalf("normalizers modified the original value: %#v", value)
+	}
+}
+
+func TestNilAsEmpty(t *testing.T) {
+	cases := []struct {
+		control   interface{}
+		candidate interface{}
+	}{
+		{[]string(nil), []string{}},
+		{map[string]int{}, map[string]int(nil)},
+		{order{Items: nil}, order{Items: []item{}}},
+	}
+
+	n := NilAsEmpty()
+	for _, c := range cases {
+		if !Equal(n(c.control), n(c.candidate)) {
+			t.Fatalf("expected %#v and %#v to be equal with NilAsEmpty", c.control, c.candidate)
+		}
+	}
+}
+
+func TestNormalizersFieldPointers(t *testing.T) {
+	type inner struct {
+		A int
+		B string
+	}
+	type outer struct {
+		P *inner
+		Q *int
+	}
+
+	x := &inner{A: 1, B: "id-1"}
+	g := normalize([]Normalizer{ReplaceStrings(regexp.MustCompile(`id-\d+`), "<id>")}, outer{P: x, Q: &x.A}).(outer)
+
+	if g.P.A != 1 || g.P.B != "<id>" || *g.Q != 1 {
+		t.Fatalf("normalize got %+v and %d, expected %+v and %d", *g.P, *g.Q, inner{A: 1, B: "<id>"}, 1)
+	}
+}
+
+func TestComputeResultNormalized(t *testing.T) {
+	e := NewQuickExperiment()
+	e.AddNormalizer(SortSlices())
+
+	control := &Observation{Value: []int{1, 2, 3}}
+	candidates := []*Observation{
+		{Value: []int{3, 2, 1}},
+		{Value: []int{3, 2}},
+	}
+
+	r := gatherResult(context.Background(), e, control, candidates)
+	if len(r.Mistmaches) != 1 {
+		t.Fatalf("mismatches: got %d, expected %d", len(r.Mistmaches), 1)
+	}
+
+	if !reflect.DeepEqual(candidates[0].NormalizedValue, []int{1, 2, 3}) {
+		t.Fatalf("normalized value got %v, expected %v", candidates[0].NormalizedValue, []int{1, 2, 3})
+	}
+
+	if !reflect.DeepEqual(candidates[0].Value, []int{3, 2, 1}) {
+		t.Fatalf("value got %v, expected the raw value", candidates[0].Value)
+	}
+}
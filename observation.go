@@ -13,6 +13,23 @@ type Observation struct {
 	Duration time.Duration
 	// Value is the value returned by the behavior if any.
 	Value interface{}
+	// NormalizedValue is the value transformed by the experiment's normalizers,
+	// that Compare and Ignore receive. It's nil if the experiment doesn't have normalizers.
+	NormalizedValue interface{}
+	// CleanedValue is the value transformed by the experiment's Clean
+	// method, or the same as Value if the experiment doesn't clean values.
+	// Use it to publish values.
+	CleanedValue interface{}
 	// Error is the error returned by the behavior, if any.
 	Error error
+	// TimedOut is true if the behavior didn't finish before its timeout.
+	TimedOut bool
+	// IgnoredBy is the rule that ignored the mismatched candidate, if any.
+	IgnoredBy *IgnoreRule
+	// Diff are the differences between the control value and
+	// the value of a mismatched candidate, see Diff. They don't include
+	// the values when the experiment cleans its values, only their paths.
+	Diff []Difference
+	// Fingerprint identifies the mismatch of a candidate, see Fingerprint.
+	Fingerprint string
 }
@@ -0,0 +1,93 @@
+package scientist
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// Publisher publishes the results of experiments somewhere else,
+// like logs or metrics. Attach publishers to an experiment with
+// Facts.AddPublisher, or to every experiment with RegisterPublisher.
+type Publisher interface {
+	Publish(ctx context.Context, result Result) error
+}
+
+// PublisherFunc is an adapter to use
+// ordinary functions as publishers.
+type PublisherFunc func(ctx context.Context, result Result) error
+
+// Publish calls f(ctx, result).
+func (f PublisherFunc) Publish(ctx context.Context, result Result) error {
+	return f(ctx, result)
+}
+
+// MultiPublisher returns a publisher that publishes results to all the
+// publishers, in order. Panics in the publishers are recovered and returned
+// as *PanicError, so one publisher can't prevent the rest from publishing.
+// It returns the errors of all the publishers joined, see errors.Join.
+func MultiPublisher(publishers ...Publisher) Publisher {
+	return multiPublisher(publishers)
+}
+
+type multiPublisher []Publisher
+
+func (m multiPublisher) Publish(ctx context.Context, result Result) error {
+	var errs []error
+	for _, p := range m {
+		if err := publishSafely(ctx, p, result); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func publishSafely(ctx context.Context, p Publisher, result Result) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Name: fmt.Sprintf("%T", p), Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return p.Publish(ctx, result)
+}
+
+var (
+	publishersMu sync.Mutex
+	publishers   []Publisher
+)
+
+// RegisterPublisher adds publishers that publish
+// the results of every experiment, after the
+// experiment's own Publish method and publishers.
+func RegisterPublisher(p ...Publisher) {
+	publishersMu.Lock()
+	publishers = append(publishers, p...)
+	publishersMu.Unlock()
+}
+
+func registeredPublishers() []Publisher {
+	publishersMu.Lock()
+	defer publishersMu.Unlock()
+	return append([]Publisher(nil), publishers...)
+}
+
+// publish publishes the result with the experiment's Publish method,
+// the experiment's publishers and the registered publishers.
+func publish(ctx context.Context, e Experiment, result Result) error {
+	all := multiPublisher{e}
+	all = append(all, experimentPublishers(e)...)
+	all = append(all, registeredPublishers()...)
+	return all.Publish(ctx, result)
+}
+
+// publishOrHandle publishes the result, handling the
+// errors with the experiment's error handler.
+func publishOrHandle(ctx context.Context, e Experiment, result Result) {
+	if err := publish(ctx, e, result); err != nil {
+		onPublishError(ctx, e, result, err)
+	}
+}
@@ -0,0 +1,146 @@
+package scientist
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestMultiPublisher(t *testing.T) {
+	var called []string
+	record := func(name string, err error) Publisher {
+		return PublisherFunc(func(ctx context.Context, result Result) error {
+			called = append(called, name)
+			return err
+		})
+	}
+
+	failure := errors.New("failure")
+	p := MultiPublisher(
+		record("first", failure),
+		PublisherFunc(func(ctx context.Context, result Result) error {
+			panic("oh no!")
+		}),
+		record("last", nil),
+	)
+
+	err := p.Publish(context.Background(), Result{})
+	if len(called) != 2 || called[0] != "first" || called[1] != "last" {
+		t.Fatalf("expected all publishers to be called, got %v", called)
+	}
+
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected %v to include %v", err, failure)
+	}
+
+	var panicked *PanicError
+	if !errors.As(err, &panicked) || panicked.Value != "oh no!" {
+		t.Fatalf("expected %v to include the panic", err)
+	}
+
+	if err := MultiPublisher(record("ok", nil)).Publish(context.Background(), Result{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestRunPublishers(t *testing.T) {
+	defer func(p []Publisher) { publishers = p }(registeredPublishers())
+
+	e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+	e.Use(func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+	e.Try("candidate", func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+
+	var published []string
+	e.AddPublisher(PublisherFunc(func(ctx context.Context, result Result) error {
+		published = append(published, "experiment")
+		return nil
+	}))
+	RegisterPublisher(PublisherFunc(func(ctx context.Context, result Result) error {
+		published = append(published, "global")
+		return nil
+	}))
+
+	if _, err := Run(e); err != nil {
+		t.Fatal(err)
+	}
+
+	<-e.published
+	if len(published) != 2 || published[0] != "experiment" || published[1] != "global" {
+		t.Fatalf("expected experiment and global publishers to be called in order, got %v", published)
+	}
+}
+
+type failingPublishExperiment struct {
+	QuickExperiment
+	errs chan error
+}
+
+func (e failingPublishExperiment) OnPublishError(ctx context.Context, result Result, err error) {
+	e.errs <- err
+}
+
+func TestRunPublishErrors(t *testing.T) {
+	e := failingPublishExperiment{NewQuickExperiment(), make(chan error, 1)}
+	e.Use(func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+	e.Try("candidate", func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+
+	failure := errors.New("failure")
+	e.AddPublisher(PublisherFunc(func(ctx context.Context, result Result) error {
+		return failure
+	}))
+
+	v, err := Run(e)
+	if err != nil || v != 1 {
+		t.Fatalf("expected the control value, got %v and %v", v, err)
+	}
+
+	if err := <-e.errs; !errors.Is(err, failure) {
+		t.Fatalf("expected %v to be handled, got %v", failure, err)
+	}
+
+	ErrorOnPublish = true
+	defer func() { ErrorOnPublish = false }()
+
+	v, err = Run(e)
+	if !errors.Is(err, failure) || v != nil {
+		t.Fatalf("expected %v to be returned, got %v and %v", failure, v, err)
+	}
+}
+
+func TestRunPublishErrorsDefaultHandler(t *testing.T) {
+	defer func(h func(context.Context, Result, error)) { OnPublishError = h }(OnPublishError)
+
+	handled := make(chan error, 1)
+	OnPublishError = func(ctx context.Context, result Result, err error) {
+		handled <- err
+	}
+
+	e := NewQuickExperiment()
+	e.Use(func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+	e.Try("candidate", func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+	e.AddPublisher(PublisherFunc(func(ctx context.Context, result Result) error {
+		panic("publisher")
+	}))
+
+	if v, err := Run(e); err != nil || v != 1 {
+		t.Fatalf("expected the control value, got %v and %v", v, err)
+	}
+
+	var panicked *PanicError
+	if err := <-handled; !errors.As(err, &panicked) || panicked.Value != "publisher" {
+		t.Fatalf("expected the publisher panic to be handled, got %v", err)
+	}
+}
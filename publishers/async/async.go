@@ -0,0 +1,254 @@
+/*
+Package async publishes the results of experiments in the background,
+so slow publishers don't add latency to the code under experiment.
+
+Wrap any publisher with New, and shut it down before your program
+exits to flush the pending results:
+
+	publisher := async.New(metricsPublisher, async.Options{
+		QueueSize: 1000,
+		Drop:      async.DropOldest,
+		BatchSize: 100,
+	})
+	scientist.RegisterPublisher(publisher)
+
+	defer publisher.Shutdown(ctx)
+*/
+package async
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// ErrClosed is returned when results are published after Shutdown.
+var ErrClosed = errors.New("async: publisher is shut down")
+
+// DropPolicy decides what happens to the
+// results published when the queue is full.
+type DropPolicy int
+
+const (
+	// DropNewest drops the results published when the queue is full.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest result in the
+	// queue to make room for the new one.
+	DropOldest
+	// Block waits until there is room in the queue.
+	// Use it only when losing results is worse than
+	// slowing down the code under experiment.
+	Block
+)
+
+// BatchPublisher is implemented by publishers that
+// publish several results at once more efficiently.
+type BatchPublisher interface {
+	PublishBatch(ctx context.Context, results []scientist.Result) error
+}
+
+// Options configure a Publisher.
+type Options struct {
+	// QueueSize is the maximum number of results waiting
+	// to be published. By default, it's 1000.
+	QueueSize int
+	// Drop decides what happens to the results
+	// published when the queue is full.
+	Drop DropPolicy
+	// BatchSize is the maximum number of results published at once.
+	// Batches include the results waiting in the queue, so they're
+	// only full when results are published faster than the wrapped
+	// publisher can handle. By default, it's 1.
+	BatchSize int
+	// OnError handles the errors returned by the wrapped publisher, and its
+	// panics as *scientist.PanicError, with the results that failed: the whole
+	// batch for a BatchPublisher, or every result published one by one with
+	// its own error. By default, errors are handled by
+	// scientist.OnPublishError for every result.
+	OnError func(results []scientist.Result, err error)
+}
+
+// Publisher publishes results in the background with another publisher.
+// Results are queued and published in order, in batches if the wrapped
+// publisher implements BatchPublisher. Otherwise, the results in a
+// batch are published one by one.
+type Publisher struct {
+	publisher scientist.Publisher
+	opts      Options
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []scientist.Result
+	closed  bool
+	dropped uint64
+	done    chan struct{}
+}
+
+// New creates a Publisher that publishes results with the given
+// publisher, and starts publishing them in the background.
+func New(publisher scientist.Publisher, opts Options) *Publisher {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(results []scientist.Result, err error) {
+			for _, r := range results {
+				scientist.OnPublishError(context.Background(), r, err)
+			}
+		}
+	}
+
+	p := &Publisher{
+		publisher: publisher,
+		opts:      opts,
+		done:      make(chan struct{}),
+	}
+	p.cond = sync.NewCond(&p.mu)
+
+	go p.run()
+	return p
+}
+
+// Publish queues the result to be published in the background.
+// Results dropped because the queue is full are counted, see Dropped,
+// and don't return errors. It returns ErrClosed after Shutdown.
+// The wrapped publisher doesn't receive ctx, since it's usually
+// canceled before results are published.
+func (p *Publisher) Publish(ctx context.Context, result scientist.Result) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for !p.closed && len(p.queue) >= p.opts.QueueSize {
+		switch p.opts.Drop {
+		case DropNewest:
+			p.dropped++
+			return nil
+		case DropOldest:
+			p.queue = p.queue[1:]
+			p.dropped++
+		case Block:
+			p.cond.Wait()
+		}
+	}
+
+	if p.closed {
+		p.dropped++
+		return ErrClosed
+	}
+
+	p.queue = append(p.queue, result)
+	p.cond.Broadcast()
+	return nil
+}
+
+// Dropped returns the number of results that
+// were dropped because the queue was full, or
+// because they were published after Shutdown.
+func (p *Publisher) Dropped() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dropped
+}
+
+// Pending returns the number of results waiting to be published.
+func (p *Publisher) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue)
+}
+
+// Shutdown stops accepting results and waits until the pending results
+// are published, or the context is done. In that case, it returns the
+// context's error and the rest of the results keep publishing in the background.
+func (p *Publisher) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Publisher) run() {
+	defer close(p.done)
+
+	for {
+		batch, ok := p.next()
+		if !ok {
+			return
+		}
+
+		p.publish(batch)
+	}
+}
+
+// next waits for the next batch of results. It returns
+// false when the publisher is shut down and the queue is empty.
+func (p *Publisher) next() ([]scientist.Result, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.queue) == 0 && !p.closed {
+		p.cond.Wait()
+	}
+
+	if len(p.queue) == 0 {
+		return nil, false
+	}
+
+	n := len(p.queue)
+	if n > p.opts.BatchSize {
+		n = p.opts.BatchSize
+	}
+
+	batch := append([]scientist.Result(nil), p.queue[:n]...)
+	p.queue = p.queue[n:]
+	p.cond.Broadcast()
+	return batch, true
+}
+
+// publish publishes a batch, handling its errors with OnError.
+func (p *Publisher) publish(batch []scientist.Result) {
+	ctx := context.Background()
+	if b, ok := p.publisher.(BatchPublisher); ok {
+		err := p.safely(func() error {
+			return b.PublishBatch(ctx, batch)
+		})
+		if err != nil {
+			p.opts.OnError(batch, err)
+		}
+		return
+	}
+
+	for _, r := range batch {
+		err := p.safely(func() error {
+			return p.publisher.Publish(ctx, r)
+		})
+		if err != nil {
+			p.opts.OnError([]scientist.Result{r}, err)
+		}
+	}
+}
+
+// safely calls fn, recovering from the panics of the wrapped publisher.
+func (p *Publisher) safely(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &scientist.PanicError{Name: fmt.Sprintf("%T", p.publisher), Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn()
+}
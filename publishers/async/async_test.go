@@ -0,0 +1,246 @@
+package async
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// gatedPublisher records the seeds of the results it publishes,
+// waiting for the gate to open before publishing each batch.
+type gatedPublisher struct {
+	gate chan struct{}
+
+	mu      sync.Mutex
+	batches [][]int64
+}
+
+func (g *gatedPublisher) PublishBatch(ctx context.Context, results []scientist.Result) error {
+	<-g.gate
+
+	var seeds []int64
+	for _, r := range results {
+		seeds = append(seeds, r.Seed)
+	}
+
+	g.mu.Lock()
+	g.batches = append(g.batches, seeds)
+	g.mu.Unlock()
+	return nil
+}
+
+func (g *gatedPublisher) Publish(ctx context.Context, result scientist.Result) error {
+	return g.PublishBatch(ctx, []scientist.Result{result})
+}
+
+func (g *gatedPublisher) published() []int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var seeds []int64
+	for _, b := range g.batches {
+		seeds = append(seeds, b...)
+	}
+	return seeds
+}
+
+func result(seed int64) scientist.Result {
+	return scientist.Result{Seed: seed}
+}
+
+// waitPending waits until the publisher is busy
+// with the first result and n results are queued.
+func waitPending(t *testing.T, p *Publisher, n int) {
+	for i := 0; i < 100; i++ {
+		if p.Pending() == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("pending: got %d, expected %d", p.Pending(), n)
+}
+
+func TestPublishBatches(t *testing.T) {
+	g := &gatedPublisher{gate: make(chan struct{})}
+	p := New(g, Options{BatchSize: 2})
+
+	ctx := context.Background()
+	p.Publish(ctx, result(1))
+	waitPending(t, p, 0)
+
+	for i := int64(2); i <= 4; i++ {
+		p.Publish(ctx, result(i))
+	}
+	close(g.gate)
+
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := g.published(); len(got) != 4 || got[0] != 1 || got[3] != 4 {
+		t.Fatalf("expected results to be published in order, got %v", got)
+	}
+
+	if len(g.batches) != 3 || len(g.batches[1]) != 2 {
+		t.Fatalf("expected results to be batched, got %v", g.batches)
+	}
+
+	if err := p.Publish(ctx, result(5)); err != ErrClosed {
+		t.Fatalf("expected %v, got %v", ErrClosed, err)
+	}
+}
+
+func TestDropPolicies(t *testing.T) {
+	cases := []struct {
+		drop     DropPolicy
+		expected []int64
+	}{
+		{DropNewest, []int64{1, 2, 3}},
+		{DropOldest, []int64{1, 4, 5}},
+	}
+
+	for _, c := range cases {
+		g := &gatedPublisher{gate: make(chan struct{})}
+		p := New(g, Options{QueueSize: 2, Drop: c.drop})
+
+		ctx := context.Background()
+		p.Publish(ctx, result(1))
+		waitPending(t, p, 0)
+
+		for i := int64(2); i <= 5; i++ {
+			p.Publish(ctx, result(i))
+		}
+
+		if p.Dropped() != 2 {
+			t.Fatalf("dropped: got %d, expected %d", p.Dropped(), 2)
+		}
+
+		close(g.gate)
+		p.Shutdown(ctx)
+
+		got := g.published()
+		if len(got) != len(c.expected) {
+			t.Fatalf("expected %v, got %v", c.expected, got)
+		}
+		for i := range got {
+			if got[i] != c.expected[i] {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+		}
+	}
+}
+
+func TestDropBlock(t *testing.T) {
+	g := &gatedPublisher{gate: make(chan struct{})}
+	p := New(g, Options{QueueSize: 1, Drop: Block})
+
+	ctx := context.Background()
+	p.Publish(ctx, result(1))
+	waitPending(t, p, 0)
+	p.Publish(ctx, result(2))
+
+	published := make(chan struct{})
+	go func() {
+		p.Publish(ctx, result(3))
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("expected publish to block while the queue is full")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(g.gate)
+	<-published
+	p.Shutdown(ctx)
+
+	if got := g.published(); len(got) != 3 || p.Dropped() != 0 {
+		t.Fatalf("expected all results to be published, got %v", got)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	g := &gatedPublisher{gate: make(chan struct{})}
+	p := New(g, Options{})
+	p.Publish(context.Background(), result(1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := p.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	close(g.gate)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishErrors(t *testing.T) {
+	failure := errors.New("failure")
+	errs := make(chan error, 2)
+
+	p := New(scientist.PublisherFunc(func(ctx context.Context, result scientist.Result) error {
+		if result.Seed == 1 {
+			panic("oh no!")
+		}
+		return failure
+	}), Options{
+		OnError: func(results []scientist.Result, err error) {
+			errs <- err
+		},
+	})
+
+	p.Publish(context.Background(), result(1))
+	p.Publish(context.Background(), result(2))
+	p.Shutdown(context.Background())
+
+	var panicked *scientist.PanicError
+	if err := <-errs; !errors.As(err, &panicked) || panicked.Value != "oh no!" {
+		t.Fatalf("expected the panic to be handled, got %v", err)
+	}
+
+	if err := <-errs; !errors.Is(err, failure) {
+		t.Fatalf("expected %v to be handled, got %v", failure, err)
+	}
+}
+
+func TestPublishErrorsInBatch(t *testing.T) {
+	failure := errors.New("failure")
+	release := make(chan struct{})
+
+	var failed [][]scientist.Result
+	p := New(scientist.PublisherFunc(func(ctx context.Context, result scientist.Result) error {
+		switch result.Seed {
+		case 0:
+			<-release
+		case 2:
+			return failure
+		}
+		return nil
+	}), Options{
+		BatchSize: 2,
+		OnError: func(results []scientist.Result, err error) {
+			failed = append(failed, results)
+		},
+	})
+
+	p.Publish(context.Background(), result(0))
+	for p.Pending() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	p.Publish(context.Background(), result(1))
+	p.Publish(context.Background(), result(2))
+	close(release)
+	p.Shutdown(context.Background())
+
+	if len(failed) != 1 || len(failed[0]) != 1 || failed[0][0].Seed != 2 {
+		t.Fatalf("expected only the failed result to be handled, got %v", failed)
+	}
+}
@@ -0,0 +1,127 @@
+package jsonl
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// RotateOptions configure when a File rotates.
+// Files without limits never rotate.
+type RotateOptions struct {
+	// MaxSize is the maximum size of the file in bytes.
+	MaxSize int64
+	// MaxAge is the maximum time the file is written to
+	// since it was opened.
+	MaxAge time.Duration
+	// OnError handles the errors rotating the file. The file keeps
+	// being written when it can't be rotated, and rotating it is tried
+	// again on the next write. By default, errors are logged.
+	OnError func(err error)
+}
+
+// File is a file that rotates when it's too large or too old.
+// Rotated files are renamed with the time of the rotation as a suffix,
+// like `scientist.jsonl.20160102T150405.000000000`, and a new file is
+// opened with the original path. Writes are never split between files.
+type File struct {
+	path string
+	opts RotateOptions
+	now  func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// OpenFile opens a file to append to, creating it if it doesn't exist.
+func OpenFile(path string, opts RotateOptions) (*File, error) {
+	if opts.OnError == nil {
+		opts.OnError = func(err error) {
+			log.Printf("jsonl: error rotating %s: %v", path, err)
+		}
+	}
+
+	f := &File{
+		path: path,
+		opts: opts,
+		now:  time.Now,
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes b to the file, rotating it first
+// if writing b would exceed its limits. Errors rotating
+// the file are handled by RotateOptions.OnError.
+func (f *File) Write(b []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.shouldRotate(int64(len(b))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(b)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+func (f *File) shouldRotate(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.size+n > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.MaxAge > 0 && f.now().Sub(f.opened) >= f.opts.MaxAge
+}
+
+// rotate renames the file and opens a new one. The file is
+// reopened even if it can't be closed or renamed, to keep writing
+// to it, and those errors are handled by RotateOptions.OnError.
+// It only returns an error if the file can't be opened.
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		f.opts.OnError(err)
+	}
+
+	rotated := f.path + "." + f.now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(f.path, rotated); err != nil {
+		f.opts.OnError(err)
+	}
+
+	return f.open()
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.opened = f.now()
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Package jsonl publishes the results of experiments as JSON Lines,
+one JSON document per result, see scientist.JSONSchemaVersion.
+
+Write to any io.Writer, like os.Stdout, or to a file
+that rotates by size and age with OpenFile:
+
+	file, err := jsonl.OpenFile("/var/log/scientist.jsonl", jsonl.RotateOptions{
+		MaxSize: 100 << 20,
+		MaxAge:  24 * time.Hour,
+	})
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scientist.RegisterPublisher(jsonl.New(file, jsonl.Options{}))
+*/
+package jsonl
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// Options configure a Publisher.
+type Options struct {
+	// OmitValues removes the values returned by the behaviors,
+	// and the values in their differences, from the results.
+	// Use it when the values are large or sensitive.
+	OmitValues bool
+}
+
+// Publisher writes results as JSON Lines to a writer.
+// It's safe to publish results concurrently, every
+// result is written with a single call to Write.
+type Publisher struct {
+	mu   sync.Mutex
+	w    io.Writer
+	opts Options
+}
+
+// New creates a Publisher that writes results to w.
+func New(w io.Writer, opts Options) *Publisher {
+	return &Publisher{
+		w:    w,
+		opts: opts,
+	}
+}
+
+// Publish writes the result as a JSON document in its own line.
+func (p *Publisher) Publish(ctx context.Context, result scientist.Result) error {
+	if p.opts.OmitValues {
+		result = withoutValues(result)
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err = p.w.Write(b)
+	return err
+}
+
+// withoutValues returns a copy of the result without values.
+func withoutValues(r scientist.Result) scientist.Result {
+	seen := make(map[*scientist.Observation]*scientist.Observation)
+	strip := func(o *scientist.Observation) *scientist.Observation {
+		if o == nil {
+			return nil
+		}
+		if s, ok := seen[o]; ok {
+			return s
+		}
+
+		s := *o
+		s.Value, s.NormalizedValue, s.CleanedValue = nil, nil, nil
+		s.Diff = make([]scientist.Difference, len(o.Diff))
+		for i, d := range o.Diff {
+			d.Control, d.Candidate = nil, nil
+			s.Diff[i] = d
+		}
+
+		seen[o] = &s
+		return &s
+	}
+	stripAll := func(os []*scientist.Observation) []*scientist.Observation {
+		if os == nil {
+			return nil
+		}
+		stripped := make([]*scientist.Observation, len(os))
+		for i, o := range os {
+			stripped[i] = strip(o)
+		}
+		return stripped
+	}
+
+	r.Control = strip(r.Control)
+	r.Candidates = stripAll(r.Candidates)
+	r.Mistmaches = stripAll(r.Mistmaches)
+	r.Ignored = stripAll(r.Ignored)
+	r.TimedOut = stripAll(r.TimedOut)
+	return r
+}
@@ -0,0 +1,165 @@
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calavera/go-scientist"
+	"github.com/calavera/go-scientist/internal/scientisttest"
+	"golang.org/x/net/context"
+)
+
+func TestPublishConcurrently(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(&buf, Options{})
+	result := scientisttest.Result(t, "experiment", map[string]string{"secret": "control value"}, scientisttest.Candidate{
+		Name:  "candidate",
+		Value: map[string]string{"secret": "candidate value"},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.Publish(context.Background(), result); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var decoded scientist.Result
+		if err := json.Unmarshal(scanner.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Name() != result.Name() || len(decoded.Mistmaches) != 1 {
+			t.Fatalf("expected result to be written, got %s", scanner.Text())
+		}
+		lines++
+	}
+
+	if lines != 10 {
+		t.Fatalf("lines: got %d, expected %d", lines, 10)
+	}
+}
+
+func TestPublishOmitValues(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(&buf, Options{OmitValues: true})
+	result := scientisttest.Result(t, "experiment", map[string]string{"secret": "control value"}, scientisttest.Candidate{
+		Name:  "candidate",
+		Value: map[string]string{"secret": "candidate value"},
+	})
+
+	if err := p.Publish(context.Background(), result); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), " value") {
+		t.Fatalf("expected values to be omitted, got %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), `"path":"[\"secret\"]"`) {
+		t.Fatalf("expected differences to be written, got %s", buf.String())
+	}
+
+	if result.Control.CleanedValue == nil || result.Mistmaches[0].Diff[0].Control == nil {
+		t.Fatal("expected the published result to be unchanged")
+	}
+}
+
+func TestFileRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scientist.jsonl")
+
+	now := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+	f, err := OpenFile(path, RotateOptions{MaxSize: 10, MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.now = func() time.Time { return now }
+
+	write := func(s string) {
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("12345\n")
+	write("1234\n")
+
+	now = now.Add(time.Minute)
+	write("12345\n")
+
+	now = now.Add(time.Hour)
+	write("1\n")
+
+	files, _ := filepath.Glob(path + "*")
+	if len(files) != 4 {
+		t.Fatalf("files: got %v, expected %d", files, 4)
+	}
+
+	b, _ := os.ReadFile(path)
+	if string(b) != "1\n" {
+		t.Fatalf("expected the last write in the current file, got %q", b)
+	}
+
+	b, _ = os.ReadFile(path + ".20160102T150505.000000000")
+	if string(b) != "1234\n" {
+		t.Fatalf("expected the file to rotate by size, got %q", b)
+	}
+
+	b, _ = os.ReadFile(path + ".20160102T160505.000000000")
+	if string(b) != "12345\n" {
+		t.Fatalf("expected the file to rotate by age, got %q", b)
+	}
+}
+
+func TestFileRotationFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scientist.jsonl")
+
+	var errs []error
+	f, err := OpenFile(path, RotateOptions{MaxSize: 10, OnError: func(err error) {
+		errs = append(errs, err)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// rotated files can't be created in a directory
+	// with the name that the file is renamed to.
+	now := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	if err := os.MkdirAll(filepath.Join(path+".20160102T150405.000000000", "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"12345\n", "123456\n", "1\n"} {
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("errors: got %v, expected %d", errs, 2)
+	}
+
+	b, _ := os.ReadFile(path)
+	if string(b) != "12345\n123456\n1\n" {
+		t.Fatalf("expected every write in the current file, got %q", b)
+	}
+}
@@ -0,0 +1,252 @@
+/*
+Package prometheus exposes the metrics of experiments
+in the Prometheus text exposition format.
+
+The publisher counts how many times every behavior runs, fails, panics
+and times out, how many times every candidate matches, mismatches and
+is ignored, and tracks the latency of every behavior in a histogram.
+It's also an http.Handler that serves the metrics:
+
+	metrics := prometheus.New(prometheus.Options{})
+	scientist.RegisterPublisher(metrics)
+
+	http.Handle("/metrics/scientist", metrics)
+
+Metrics are labeled with the name of the experiment and the name of
+the behavior, `__control__` for the control behavior, like in
+scientist.Result.Order, so it never collides with a candidate:
+
+	scientist_mismatches_total{experiment="my experiment",behavior="new code"} 3
+*/
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// DefaultBuckets are the upper bounds of the latency histogram
+// buckets by default, in seconds, from 0.5ms to 10s.
+var DefaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Options configure a Publisher.
+type Options struct {
+	// Namespace is the prefix of all the metric names.
+	// By default, it's `scientist`.
+	Namespace string
+	// Buckets are the upper bounds of the latency
+	// histogram buckets, in seconds, in increasing order.
+	// By default, they're DefaultBuckets.
+	Buckets []float64
+}
+
+// Publisher collects the metrics of experiments.
+// It's safe to publish results and serve metrics concurrently.
+type Publisher struct {
+	opts Options
+
+	mu     sync.Mutex
+	series map[seriesKey]*series
+}
+
+type seriesKey struct {
+	experiment string
+	behavior   string
+}
+
+// series holds the metrics of a behavior.
+type series struct {
+	candidate bool
+
+	runs       uint64
+	errors     uint64
+	panics     uint64
+	timeouts   uint64
+	matches    uint64
+	mismatches uint64
+	ignored    uint64
+
+	buckets []uint64
+	sum     float64
+}
+
+// New creates a Publisher without metrics.
+func New(opts Options) *Publisher {
+	if opts.Namespace == "" {
+		opts.Namespace = "scientist"
+	}
+	if opts.Buckets == nil {
+		opts.Buckets = DefaultBuckets
+	}
+
+	return &Publisher{
+		opts:   opts,
+		series: make(map[seriesKey]*series),
+	}
+}
+
+// Publish records the metrics of the result.
+// Errors don't include panics and timeouts, they're counted apart.
+func (p *Publisher) Publish(ctx context.Context, result scientist.Result) error {
+	outcomes := make(map[*scientist.Observation]string)
+	for _, o := range result.Mistmaches {
+		outcomes[o] = "mismatch"
+	}
+	for _, o := range result.Ignored {
+		outcomes[o] = "ignored"
+	}
+	for _, o := range result.TimedOut {
+		outcomes[o] = "timeout"
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if result.Control != nil {
+		p.record(result.Name(), "__control__", result.Control)
+	}
+
+	for _, c := range result.Candidates {
+		s := p.record(result.Name(), c.Name, c)
+		s.candidate = true
+
+		switch outcomes[c] {
+		case "mismatch":
+			s.mismatches++
+		case "ignored":
+			s.ignored++
+		case "timeout":
+			// counted by record.
+		default:
+			s.matches++
+		}
+	}
+
+	return nil
+}
+
+// record records the metrics of an observation
+// that don't depend on the comparison.
+func (p *Publisher) record(experiment, behavior string, o *scientist.Observation) *series {
+	key := seriesKey{experiment, behavior}
+	s, ok := p.series[key]
+	if !ok {
+		s = &series{buckets: make([]uint64, len(p.opts.Buckets))}
+		p.series[key] = s
+	}
+
+	s.runs++
+
+	_, panicked := o.Error.(*scientist.PanicError)
+	switch {
+	case o.TimedOut:
+		s.timeouts++
+	case panicked:
+		s.panics++
+	case o.Error != nil:
+		s.errors++
+	}
+
+	seconds := o.Duration.Seconds()
+	s.sum += seconds
+	for i, b := range p.opts.Buckets {
+		if seconds <= b {
+			s.buckets[i]++
+		}
+	}
+
+	return s
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (p *Publisher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+// counters are the counters of every series, in the order they're written.
+var counters = []struct {
+	name      string
+	help      string
+	candidate bool
+	value     func(*series) uint64
+}{
+	{"runs_total", "Number of times a behavior ran.", false, func(s *series) uint64 { return s.runs }},
+	{"errors_total", "Number of times a behavior returned an error.", false, func(s *series) uint64 { return s.errors }},
+	{"panics_total", "Number of times a behavior panicked.", false, func(s *series) uint64 { return s.panics }},
+	{"timeouts_total", "Number of times a behavior timed out.", false, func(s *series) uint64 { return s.timeouts }},
+	{"matches_total", "Number of times a candidate matched the control.", true, func(s *series) uint64 { return s.matches }},
+	{"mismatches_total", "Number of times a candidate didn't match the control.", true, func(s *series) uint64 { return s.mismatches }},
+	{"ignored_total", "Number of times a candidate mismatch was ignored.", true, func(s *series) uint64 { return s.ignored }},
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+// Metrics are rendered before writing them, so slow
+// readers don't block publishing results while they read.
+func (p *Publisher) WriteTo(w io.Writer) (int64, error) {
+	return p.render().WriteTo(w)
+}
+
+// render renders the metrics in the text exposition format.
+func (p *Publisher) render() *bytes.Buffer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]seriesKey, 0, len(p.series))
+	for k := range p.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].experiment != keys[j].experiment {
+			return keys[i].experiment < keys[j].experiment
+		}
+		return keys[i].behavior < keys[j].behavior
+	})
+
+	buf := &bytes.Buffer{}
+
+	for _, c := range counters {
+		name := p.opts.Namespace + "_" + c.name
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s counter\n", name, c.help, name)
+		for _, k := range keys {
+			s := p.series[k]
+			if c.candidate && !s.candidate {
+				continue
+			}
+			fmt.Fprintf(buf, "%s{%s} %d\n", name, labels(k), c.value(s))
+		}
+	}
+
+	name := p.opts.Namespace + "_duration_seconds"
+	fmt.Fprintf(buf, "# HELP %s Latency of a behavior in seconds.\n# TYPE %s histogram\n", name, name)
+	for _, k := range keys {
+		s := p.series[k]
+		for i, b := range p.opts.Buckets {
+			fmt.Fprintf(buf, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels(k), formatFloat(b), s.buckets[i])
+		}
+		fmt.Fprintf(buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels(k), s.runs)
+		fmt.Fprintf(buf, "%s_sum{%s} %s\n", name, labels(k), formatFloat(s.sum))
+		fmt.Fprintf(buf, "%s_count{%s} %d\n", name, labels(k), s.runs)
+	}
+
+	return buf
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labels(k seriesKey) string {
+	return fmt.Sprintf(`experiment="%s",behavior="%s"`, labelEscaper.Replace(k.experiment), labelEscaper.Replace(k.behavior))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,167 @@
+package prometheus
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+func result(candidates ...*scientist.Observation) scientist.Result {
+	e := scientist.NewQuickExperiment()
+	e.SetTimeout(time.Millisecond)
+	e.AddIgnoreRule(scientist.IgnoreRule{
+		Name: "negative",
+		Ignore: func(ctx context.Context, control, candidate *scientist.Observation) bool {
+			v, _ := candidate.Value.(int)
+			return v < 0
+		},
+	})
+
+	results := make(chan scientist.Result, 1)
+	e.AddPublisher(scientist.PublisherFunc(func(ctx context.Context, result scientist.Result) error {
+		results <- result
+		return nil
+	}))
+
+	e.Use(func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+	for _, c := range candidates {
+		c := c
+		e.Try(c.Name, func(ctx context.Context) (interface{}, error) {
+			if c.TimedOut {
+				<-ctx.Done()
+			}
+			if p, ok := c.Error.(*scientist.PanicError); ok {
+				panic(p.Value)
+			}
+			return c.Value, c.Error
+		})
+	}
+
+	scientist.Run(e)
+	return <-results
+}
+
+func TestPublish(t *testing.T) {
+	p := New(Options{Buckets: []float64{0.5, 1}})
+	ctx := context.Background()
+
+	p.Publish(ctx, result(&scientist.Observation{Name: "new code", Value: 1}))
+	p.Publish(ctx, result(&scientist.Observation{Name: "new code", Value: 2}))
+	p.Publish(ctx, result(&scientist.Observation{Name: "new code", Value: -1}))
+	p.Publish(ctx, result(&scientist.Observation{Name: "new code", Value: 1, Error: errors.New("failure")}))
+	p.Publish(ctx, result(&scientist.Observation{Name: "new code", Error: &scientist.PanicError{Value: "oh no!"}}))
+	p.Publish(ctx, result(&scientist.Observation{Name: "new code", TimedOut: true}))
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("content type: got %s", ct)
+	}
+
+	body := rec.Body.String()
+	expected := []string{
+		"# TYPE scientist_runs_total counter",
+		`scientist_runs_total{experiment="experiment",behavior="__control__"} 6`,
+		`scientist_runs_total{experiment="experiment",behavior="new code"} 6`,
+		`scientist_errors_total{experiment="experiment",behavior="new code"} 1`,
+		`scientist_panics_total{experiment="experiment",behavior="new code"} 1`,
+		`scientist_timeouts_total{experiment="experiment",behavior="new code"} 1`,
+		`scientist_matches_total{experiment="experiment",behavior="new code"} 1`,
+		`scientist_mismatches_total{experiment="experiment",behavior="new code"} 3`,
+		`scientist_ignored_total{experiment="experiment",behavior="new code"} 1`,
+		"# TYPE scientist_duration_seconds histogram",
+		`scientist_duration_seconds_bucket{experiment="experiment",behavior="__control__",le="0.5"} 6`,
+		`scientist_duration_seconds_bucket{experiment="experiment",behavior="__control__",le="+Inf"} 6`,
+		`scientist_duration_seconds_count{experiment="experiment",behavior="new code"} 6`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(body, e+"\n") {
+			t.Fatalf("expected %s in:\n%s", e, body)
+		}
+	}
+
+	if strings.Contains(body, `scientist_matches_total{experiment="experiment",behavior="__control__"}`) {
+		t.Fatalf("expected candidate metrics only for candidates:\n%s", body)
+	}
+}
+
+func TestWriteToEscapesLabels(t *testing.T) {
+	p := New(Options{Namespace: "app"})
+	p.Publish(context.Background(), result(&scientist.Observation{Name: "say \"hi\"\\n", Value: 1}))
+
+	var b strings.Builder
+	n, err := p.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != int64(b.Len()) {
+		t.Fatalf("written: got %d, expected %d", n, b.Len())
+	}
+
+	if !strings.Contains(b.String(), `app_runs_total{experiment="experiment",behavior="say \"hi\"\\n"} 1`) {
+		t.Fatalf("expected labels to be escaped:\n%s", b.String())
+	}
+
+	if _, err := p.WriteTo(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// blockingWriter blocks writes until it's released.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w blockingWriter) Write(b []byte) (int, error) {
+	<-w.release
+	return len(b), nil
+}
+
+func TestWriteToDoesNotBlockPublish(t *testing.T) {
+	p := New(Options{})
+	p.Publish(context.Background(), result(&scientist.Observation{Name: "new code", Value: 1}))
+
+	w := blockingWriter{make(chan struct{})}
+	defer close(w.release)
+	go p.WriteTo(w)
+
+	published := make(chan struct{})
+	go func() {
+		p.Publish(context.Background(), result(&scientist.Observation{Name: "new code", Value: 1}))
+		close(published)
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("expected publish not to wait for slow readers")
+	}
+}
+
+func TestControlDoesNotCollide(t *testing.T) {
+	p := New(Options{})
+	p.Publish(context.Background(), result(&scientist.Observation{Name: "control", Value: 2}))
+
+	var b strings.Builder
+	p.WriteTo(&b)
+
+	for _, e := range []string{
+		`scientist_runs_total{experiment="experiment",behavior="__control__"} 1`,
+		`scientist_runs_total{experiment="experiment",behavior="control"} 1`,
+		`scientist_mismatches_total{experiment="experiment",behavior="control"} 1`,
+	} {
+		if !strings.Contains(b.String(), e+"\n") {
+			t.Fatalf("expected %s in:\n%s", e, b.String())
+		}
+	}
+}
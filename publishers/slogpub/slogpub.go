@@ -0,0 +1,76 @@
+/*
+Package slogpub publishes the results of experiments with log/slog.
+
+Every result is logged as a structured record, at a level that
+depends on its outcome, see scientist.Result.LogValue:
+
+	scientist.RegisterPublisher(slogpub.New(slog.Default(), slogpub.Options{
+		Levels: map[scientist.Outcome]slog.Level{
+			scientist.OutcomeMatched: slog.LevelDebug,
+		},
+	}))
+*/
+package slogpub
+
+import (
+	"log/slog"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// DefaultLevels are the levels results are logged at by default.
+var DefaultLevels = map[scientist.Outcome]slog.Level{
+	scientist.OutcomeMatched:    slog.LevelDebug,
+	scientist.OutcomeIgnored:    slog.LevelInfo,
+	scientist.OutcomeTimedOut:   slog.LevelWarn,
+	scientist.OutcomeMismatched: slog.LevelWarn,
+}
+
+// Options configure a Publisher.
+type Options struct {
+	// Levels are the levels results are logged at by outcome.
+	// Outcomes without a level use DefaultLevels.
+	Levels map[scientist.Outcome]slog.Level
+	// Message is the message of the records.
+	// By default, it's `scientist experiment`.
+	Message string
+}
+
+// Publisher logs results with a slog.Logger.
+// Results are logged in the `result` attribute.
+type Publisher struct {
+	logger *slog.Logger
+	opts   Options
+}
+
+// New creates a Publisher that logs results with logger,
+// or slog.Default if logger is nil.
+func New(logger *slog.Logger, opts Options) *Publisher {
+	if opts.Message == "" {
+		opts.Message = "scientist experiment"
+	}
+
+	return &Publisher{
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+// Publish logs the result, if the logger is enabled for its level.
+func (p *Publisher) Publish(ctx context.Context, result scientist.Result) error {
+	logger := p.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	logger.LogAttrs(ctx, p.level(result.Outcome()), p.opts.Message, slog.Any("result", result))
+	return nil
+}
+
+func (p *Publisher) level(outcome scientist.Outcome) slog.Level {
+	if l, ok := p.opts.Levels[outcome]; ok {
+		return l
+	}
+	return DefaultLevels[outcome]
+}
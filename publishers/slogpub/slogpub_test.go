@@ -0,0 +1,76 @@
+package slogpub
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/calavera/go-scientist"
+	"github.com/calavera/go-scientist/internal/scientisttest"
+	"golang.org/x/net/context"
+)
+
+func TestPublish(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	p := New(logger, Options{})
+
+	if err := p.Publish(context.Background(), scientisttest.Result(t, "experiment", 1, scientisttest.Candidate{Name: "candidate", Value: 1})); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() > 0 {
+		t.Fatalf("expected matches to be logged at debug level, got %s", buf.String())
+	}
+
+	if err := p.Publish(context.Background(), scientisttest.Result(t, "experiment", 1, scientisttest.Candidate{Name: "candidate", Value: 2, Error: errors.New("failure")})); err != nil {
+		t.Fatal(err)
+	}
+
+	var record struct {
+		Level  string
+		Msg    string
+		Result struct {
+			Experiment string
+			Outcome    string
+			Control    map[string]interface{}
+			Candidates map[string]map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Level != "WARN" || record.Msg != "scientist experiment" {
+		t.Fatalf("expected mismatches to be logged at warn level, got %s", buf.String())
+	}
+
+	r := record.Result
+	if r.Experiment != "experiment" || r.Outcome != "mismatched" || r.Control["duration"] == nil {
+		t.Fatalf("expected the result to be logged, got %s", buf.String())
+	}
+
+	c := r.Candidates["candidate"]
+	if c["error"] != "failure" || c["error_type"] != "*errors.errorString" || c["diff"] != ".: 1 != 2" {
+		t.Fatalf("expected the candidate to be logged, got %s", buf.String())
+	}
+}
+
+func TestPublishLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	p := New(logger, Options{
+		Levels:  map[scientist.Outcome]slog.Level{scientist.OutcomeMatched: slog.LevelInfo},
+		Message: "experiment",
+	})
+
+	if err := p.Publish(context.Background(), scientisttest.Result(t, "experiment", 1, scientisttest.Candidate{Name: "candidate", Value: 1})); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("level=INFO msg=experiment result.experiment=experiment result.outcome=matched")) {
+		t.Fatalf("expected matches to be logged at info level, got %s", buf.String())
+	}
+}
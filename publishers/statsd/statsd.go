@@ -0,0 +1,177 @@
+/*
+Package statsd publishes the metrics of experiments to StatsD over UDP.
+
+For every result, it sends the duration of every behavior as a timing,
+in milliseconds, and counts the outcome of the experiment:
+
+	scientist.my_experiment.__control__.duration:1.5|ms
+	scientist.my_experiment.new_code.duration:0.8|ms
+	scientist.my_experiment.mismatched:1|c
+
+With DogStatsD tags enabled, names don't include
+the experiment and the behaviors, tags do instead:
+
+	scientist.duration:1.5|ms|#experiment:my_experiment,behavior:__control__
+	scientist.duration:0.8|ms|#experiment:my_experiment,behavior:new_code
+	scientist.result:1|c|#experiment:my_experiment,outcome:mismatched
+
+The control behavior is always called `__control__`. Candidates whose
+names are sanitized to it are not sent, and Publish returns an error.
+*/
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// maxPacketSize is the maximum size of the packets sent,
+// to fit in the usual MTU of Ethernet networks.
+const maxPacketSize = 1432
+
+// controlName is the name of the control behavior in the metrics.
+const controlName = "__control__"
+
+var invalidName = regexp.MustCompile(`[^a-zA-Z0-9_\-]+`)
+
+// Options configure a Publisher.
+type Options struct {
+	// Prefix is the prefix of all the metric names.
+	// By default, it's `scientist`.
+	Prefix string
+	// Tags sends the experiment, the behaviors and the
+	// outcome as DogStatsD tags, instead of in the metric names.
+	Tags bool
+}
+
+// Publisher sends the metrics of experiments to StatsD.
+// It's safe to publish results concurrently.
+type Publisher struct {
+	opts Options
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// New creates a Publisher that sends metrics to the StatsD
+// server listening on the UDP address addr, like `127.0.0.1:8125`.
+func New(addr string, opts Options) (*Publisher, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Prefix == "" {
+		opts.Prefix = "scientist"
+	}
+
+	return &Publisher{
+		opts: opts,
+		conn: conn,
+	}, nil
+}
+
+// Close closes the connection to the StatsD server.
+func (p *Publisher) Close() error {
+	return p.conn.Close()
+}
+
+// Publish sends the durations of the behaviors and the outcome of the
+// experiment. Names of experiments and behaviors are sanitized, replacing
+// the characters that are not letters, digits, `_` or `-` with `_`.
+// Candidates that would be sent as the control are skipped, and
+// an error is returned after sending the rest of the metrics.
+func (p *Publisher) Publish(ctx context.Context, result scientist.Result) error {
+	experiment := Sanitize(result.Name())
+	outcome := Sanitize(string(result.Outcome()))
+
+	var metrics []string
+	if result.Control != nil {
+		metrics = append(metrics, p.timing(experiment, controlName, result.Control.Duration))
+	}
+
+	var collided []string
+	for _, c := range result.Candidates {
+		name := Sanitize(c.Name)
+		if name == controlName {
+			collided = append(collided, c.Name)
+			continue
+		}
+		metrics = append(metrics, p.timing(experiment, name, c.Duration))
+	}
+
+	if p.opts.Tags {
+		metrics = append(metrics, p.opts.Prefix+".result:1|c"+tags("experiment", experiment, "outcome", outcome))
+	} else {
+		metrics = append(metrics, p.opts.Prefix+"."+experiment+"."+outcome+":1|c")
+	}
+
+	if err := p.send(metrics); err != nil {
+		return err
+	}
+
+	if len(collided) > 0 {
+		return fmt.Errorf("statsd: candidates %q are sent as the control %s", collided, controlName)
+	}
+	return nil
+}
+
+func (p *Publisher) timing(experiment, behavior string, d time.Duration) string {
+	ms := strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
+
+	if p.opts.Tags {
+		return p.opts.Prefix + ".duration:" + ms + "|ms" + tags("experiment", experiment, "behavior", behavior)
+	}
+	return p.opts.Prefix + "." + experiment + "." + behavior + ".duration:" + ms + "|ms"
+}
+
+// tags formats pairs of keys and sanitized values as DogStatsD tags.
+func tags(pairs ...string) string {
+	t := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		t = append(t, pairs[i]+":"+pairs[i+1])
+	}
+	return "|#" + strings.Join(t, ",")
+}
+
+// send sends the metrics, as many as they fit in every packet.
+func (p *Publisher) send(metrics []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var packet []byte
+	for _, m := range metrics {
+		if len(packet) > 0 && len(packet)+1+len(m) > maxPacketSize {
+			if _, err := p.conn.Write(packet); err != nil {
+				return err
+			}
+			packet = packet[:0]
+		}
+
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, m...)
+	}
+
+	if len(packet) == 0 {
+		return nil
+	}
+
+	_, err := p.conn.Write(packet)
+	return err
+}
+
+// Sanitize replaces the characters of a metric name segment that are
+// not letters, digits, `_` or `-` with `_`, like `new code` with `new_code`.
+func Sanitize(name string) string {
+	return invalidName.ReplaceAllString(name, "_")
+}
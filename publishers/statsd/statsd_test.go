@@ -0,0 +1,145 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calavera/go-scientist"
+	"github.com/calavera/go-scientist/internal/scientisttest"
+	"golang.org/x/net/context"
+)
+
+// listen starts a local UDP listener and returns
+// its address and a function to read the next packet.
+func listen(t *testing.T) (string, func() string) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn.LocalAddr().String(), func() string {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+
+		b := make([]byte, 2*maxPacketSize)
+		n, _, err := conn.ReadFrom(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b[:n])
+	}
+}
+
+// timedResult runs an experiment with a candidate and
+// fixed durations for the behaviors, and returns its result.
+func timedResult(t *testing.T, name string, candidate interface{}) scientist.Result {
+	result := scientisttest.Result(t, "my experiment", 1, scientisttest.Candidate{Name: name, Value: candidate})
+	result.Control.Duration = 1500 * time.Microsecond
+	result.Candidates[0].Duration = 2 * time.Second
+	return result
+}
+
+func TestPublish(t *testing.T) {
+	addr, read := listen(t)
+
+	p, err := New(addr, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if err := p.Publish(context.Background(), timedResult(t, "new code", 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"scientist.my_experiment.__control__.duration:1.5|ms",
+		"scientist.my_experiment.new_code.duration:2000|ms",
+		"scientist.my_experiment.mismatched:1|c",
+	}, "\n")
+	if got := read(); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestPublishTags(t *testing.T) {
+	addr, read := listen(t)
+
+	p, err := New(addr, Options{Prefix: "app.experiments", Tags: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if err := p.Publish(context.Background(), timedResult(t, "new:code", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"app.experiments.duration:1.5|ms|#experiment:my_experiment,behavior:__control__",
+		"app.experiments.duration:2000|ms|#experiment:my_experiment,behavior:new_code",
+		"app.experiments.result:1|c|#experiment:my_experiment,outcome:matched",
+	}, "\n")
+	if got := read(); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestPublishSplitsPackets(t *testing.T) {
+	addr, read := listen(t)
+
+	p, err := New(addr, Options{Prefix: strings.Repeat("a", maxPacketSize/2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if err := p.Publish(context.Background(), timedResult(t, "candidate", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, suffix := range []string{"__control__.duration:1.5|ms", "candidate.duration:2000|ms", "matched:1|c"} {
+		if got := read(); !strings.HasSuffix(got, suffix) || strings.Contains(got, "\n") {
+			t.Fatalf("expected a packet for %s, got %q", suffix, got)
+		}
+	}
+}
+
+func TestPublishControlCollision(t *testing.T) {
+	addr, read := listen(t)
+
+	p, err := New(addr, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if err := p.Publish(context.Background(), timedResult(t, "._control_.", 1)); err == nil {
+		t.Fatal("expected an error for a candidate sent as the control")
+	}
+
+	expected := strings.Join([]string{
+		"scientist.my_experiment.__control__.duration:1.5|ms",
+		"scientist.my_experiment.matched:1|c",
+	}, "\n")
+	if got := read(); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	cases := map[string]string{
+		"new code":         "new_code",
+		"timed out":        "timed_out",
+		"a.b:c|d#e,f@g":    "a_b_c_d_e_f_g",
+		"already-fine_123": "already-fine_123",
+	}
+
+	for name, expected := range cases {
+		if got := Sanitize(name); got != expected {
+			t.Fatalf("sanitize %q: got %q, expected %q", name, got, expected)
+		}
+	}
+}
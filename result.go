@@ -1,11 +1,15 @@
 package scientist
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Result holds information about
 // an executed experiment.
 type Result struct {
-	name string
+	name    string
+	cleaned bool
 	// Control is the result of executing the control behavior.
 	Control *Observation
 	// Candidates are the results of executing all the candidate behaviors.
@@ -14,11 +18,80 @@ type Result struct {
 	Mistmaches []*Observation
 	// Ignored are the results of behaviors that can be ignored.
 	Ignored []*Observation
+	// TimedOut are the results of behaviors that didn't finish before their timeout.
+	// They are not compared with the control.
+	TimedOut []*Observation
+	// Strategy is how the behaviors were executed.
+	Strategy Strategy
+	// Order is the order in which the behaviors were executed.
+	// The control behavior is called `__control__`.
+	Order []string
+	// Seed is the seed used to shuffle the behaviors, see Facts.SetSeed.
+	// It's zero if the experiment doesn't implement NextSeed and ShuffleWithSeed.
+	Seed int64
+	// Sampling is the sampling decision for the run,
+	// when the experiment has a Sampler.
+	Sampling *Sampling
+	// Tripped are the names of the candidates whose
+	// circuit breaker opened after this run.
+	Tripped []string
+	// Skipped are the names of the candidates that didn't
+	// run because their circuit breaker was open.
+	Skipped []string
 }
 
-// Matches returns true if there are no mismatches and ignored observations.
+// Name returns the name of the experiment.
+func (r Result) Name() string {
+	return r.name
+}
+
+// Matches returns true if there are no mismatches, ignored and timed out observations.
 func (r Result) Matches() bool {
-	return len(r.Mistmaches) == 0 && len(r.Ignored) == 0
+	return len(r.Mistmaches) == 0 && len(r.Ignored) == 0 && len(r.TimedOut) == 0
+}
+
+// Outcome summarizes the result of an experiment.
+type Outcome string
+
+const (
+	// OutcomeMatched means that all the candidates match the control.
+	OutcomeMatched Outcome = "matched"
+	// OutcomeMismatched means that some candidates don't match the control.
+	OutcomeMismatched Outcome = "mismatched"
+	// OutcomeTimedOut means that some candidates didn't finish
+	// before their timeout, and the rest match the control.
+	OutcomeTimedOut Outcome = "timed out"
+	// OutcomeIgnored means that some mismatches were ignored,
+	// and the rest of the candidates match the control.
+	OutcomeIgnored Outcome = "ignored"
+)
+
+// Outcome returns the outcome of the experiment.
+// Mismatches take precedence over timeouts,
+// and timeouts over ignored mismatches.
+func (r Result) Outcome() Outcome {
+	switch {
+	case len(r.Mistmaches) > 0:
+		return OutcomeMismatched
+	case len(r.TimedOut) > 0:
+		return OutcomeTimedOut
+	case len(r.Ignored) > 0:
+		return OutcomeIgnored
+	default:
+		return OutcomeMatched
+	}
+}
+
+// IgnoreCounts returns the number of observations
+// ignored by every rule, by the name of the rule.
+func (r Result) IgnoreCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, o := range r.Ignored {
+		if o.IgnoredBy != nil {
+			counts[o.IgnoredBy.Name]++
+		}
+	}
+	return counts
 }
 
 // MismatchError holds the result information
@@ -33,6 +106,21 @@ func (m MismatchError) MismatchResult() Result {
 }
 
 // Error returns the string representation of the MismatchError.
+// It includes the differences of the mismatched observations, or their
+// cleaned values if the experiment cleans its values or there are no
+// differences between the values. Differences of experiments that clean
+// their values only include their paths, see Difference.String.
 func (m MismatchError) Error() string {
-	return fmt.Sprintf("expriment `%s` has %d mismatched results", m.result.name, len(m.result.Mistmaches))
+	mismatches := make([]string, len(m.result.Mistmaches))
+	for i, o := range m.result.Mistmaches {
+		switch {
+		case len(o.Diff) > 0 && !m.result.cleaned:
+			mismatches[i] = fmt.Sprintf("`%s` differs at %s", o.Name, formatDiff(o.Diff))
+		case len(o.Diff) > 0:
+			mismatches[i] = fmt.Sprintf("`%s` returned %v, control returned %v, differs at %s", o.Name, o.CleanedValue, m.result.Control.CleanedValue, formatDiff(o.Diff))
+		default:
+			mismatches[i] = fmt.Sprintf("`%s` returned %v, control returned %v", o.Name, o.CleanedValue, m.result.Control.CleanedValue)
+		}
+	}
+	return fmt.Sprintf("expriment `%s` has %d mismatched results: %s", m.result.name, len(m.result.Mistmaches), strings.Join(mismatches, ", "))
 }
@@ -0,0 +1,75 @@
+package scientist
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+
+	"golang.org/x/net/context"
+)
+
+// Sampler enables an experiment for a percentage of its runs.
+// See Facts.SetSampler.
+type Sampler struct {
+	// Percent is the percentage of runs, between 0 and 100,
+	// where the experiment is enabled.
+	Percent float64
+	// Key extracts a stable identifier from the context, like a user ID.
+	// Runs of an experiment with the same key are always sampled the same way.
+	// Runs without a key are sampled randomly.
+	Key func(ctx context.Context) (string, bool)
+}
+
+// Sampling holds the sampling decision for a run.
+type Sampling struct {
+	// Percent is the percentage of runs where the experiment is enabled.
+	Percent float64
+	// Key is the identifier extracted from the context, if any.
+	Key string
+	// Keyed is true if the decision was made with a key.
+	Keyed bool
+	// Bucket is the position of the run, between 0 and 100.
+	// Runs in buckets lower than Percent are sampled.
+	Bucket float64
+	// Sampled is true if the experiment is enabled for the run.
+	Sampled bool
+}
+
+// ContextKey returns a function that extracts a sampling key
+// from the context value with the given key.
+func ContextKey(key interface{}) func(context.Context) (string, bool) {
+	return func(ctx context.Context) (string, bool) {
+		v := ctx.Value(key)
+		if v == nil {
+			return "", false
+		}
+		return fmt.Sprint(v), true
+	}
+}
+
+// Sample decides if an experiment is enabled for a run.
+// Keys are hashed with the experiment's name, so the decision for a key
+// is the same as long as the percentage doesn't change, and experiments
+// with the same percentage don't sample the same keys.
+func (s *Sampler) Sample(ctx context.Context, experiment string) Sampling {
+	sampling := Sampling{
+		Percent: s.Percent,
+	}
+
+	if s.Key != nil {
+		sampling.Key, sampling.Keyed = s.Key(ctx)
+	}
+
+	if sampling.Keyed {
+		h := fnv.New64a()
+		h.Write([]byte(experiment))
+		h.Write([]byte{0})
+		h.Write([]byte(sampling.Key))
+		sampling.Bucket = float64(h.Sum64()%10000) / 100
+	} else {
+		sampling.Bucket = rand.Float64() * 100
+	}
+
+	sampling.Sampled = sampling.Bucket < s.Percent
+	return sampling
+}
@@ -0,0 +1,105 @@
+package scientist
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestSamplerKeyed(t *testing.T) {
+	s := &Sampler{
+		Percent: 50,
+		Key:     ContextKey("user_id"),
+	}
+
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		ctx := context.WithValue(context.Background(), "user_id", i)
+
+		first := s.Sample(ctx, "experiment")
+		if !first.Keyed {
+			t.Fatalf("expected sampling for user %d to be keyed", i)
+		}
+
+		for j := 0; j < 3; j++ {
+			if again := s.Sample(ctx, "experiment"); again != first {
+				t.Fatalf("sampling for user %d changed: got %v, expected %v", i, again, first)
+			}
+		}
+
+		if first.Sampled {
+			sampled++
+		}
+	}
+
+	if sampled < 400 || sampled > 600 {
+		t.Fatalf("sampled got %d of 1000 users, expected around 500", sampled)
+	}
+}
+
+func TestSamplerExperiments(t *testing.T) {
+	s := &Sampler{
+		Percent: 50,
+		Key:     ContextKey("user_id"),
+	}
+
+	different := 0
+	for i := 0; i < 1000; i++ {
+		ctx := context.WithValue(context.Background(), "user_id", i)
+		if s.Sample(ctx, "a").Sampled != s.Sample(ctx, "b").Sampled {
+			different++
+		}
+	}
+
+	if different < 400 || different > 600 {
+		t.Fatalf("got %d of 1000 users sampled differently by two experiments, expected around 500", different)
+	}
+}
+
+func TestSamplerBounds(t *testing.T) {
+	ctx := context.Background()
+
+	for i := 0; i < 100; i++ {
+		if (&Sampler{Percent: 0}).Sample(ctx, "experiment").Sampled {
+			t.Fatal("expected 0% sampler to never sample")
+		}
+
+		if !(&Sampler{Percent: 100}).Sample(ctx, "experiment").Sampled {
+			t.Fatal("expected 100% sampler to always sample")
+		}
+	}
+}
+
+func TestRunSampled(t *testing.T) {
+	e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+	e.SetSampler(&Sampler{Percent: 100, Key: ContextKey("user_id")})
+
+	e.Use(func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	e.Try("test", func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	ctx := context.WithValue(context.Background(), "user_id", 1)
+	if _, err := RunWithContext(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+
+	r := <-e.published
+	if r.Sampling == nil || !r.Sampling.Sampled || r.Sampling.Key != "1" {
+		t.Fatalf("got sampling %v, expected sampled with key 1", r.Sampling)
+	}
+
+	e.SetSampler(&Sampler{Percent: 0})
+	if _, err := RunWithContext(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case r := <-e.published:
+		t.Fatalf("expected experiment to not run, got %v", r)
+	default:
+	}
+}
@@ -0,0 +1,29 @@
+package samples
+
+import (
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+func exampleSamplingExperiment() {
+	e := scientist.NewQuickExperiment()
+
+	// enable the experiment for 25% of the users.
+	e.SetSampler(&scientist.Sampler{
+		Percent: 25,
+		Key:     scientist.ContextKey("user_id"),
+	})
+
+	e.Use(func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+
+	e.Try("new_feature", func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, "user_id", 1)
+
+	scientist.RunWithContext(ctx, e)
+}
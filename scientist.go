@@ -44,6 +44,28 @@ The easiest way to do this is by composing your own experiments with `QuickExper
 and implementing the methods you want to change, most likely `Name`, `IsEnabled`, `Ignore`,
 `Compare` and `Publish`. You can see several examples of this in the `samples` package.
 
+By default, `QuickExperiment` compares values deeply with `scientist.Equal`, so it works with
+slices, maps and structs, and errors with `scientist.EqualErrors`, that considers two errors
+equivalent if one wraps the other or both have the same type and message.
+Nil slices and maps are different from empty ones, like `null` and `[]` in JSON.
+
+Struct fields that legitimately differ between behaviors, like generated IDs and timestamps,
+can be excluded from the comparison with the `scientist` tag. Tags are also honored by `scientist.Diff`:
+
+	type Invoice struct {
+		ID      string    `scientist:"-"`
+		Total   float64   `scientist:"tolerance=0.01"`
+		Created time.Time `scientist:"tolerance=1ms"`
+	}
+
+Use `Facts.SetComparator` to compare observations with one of the comparators
+in the `compare` package instead of overriding `Compare`:
+
+	experiment.SetComparator(compare.And(
+		compare.OnField("Total", compare.FloatTolerance(0.01)),
+		compare.ErrorMessage,
+	))
+
 Failing with mismatches
 
 `scientist.Run` guarantees that the control behavior, your old code, always returns its values.
@@ -54,6 +76,271 @@ To enable this, you can set the global variable `scientist.ErrorOnMismatch` to `
 In case of mismatched observations, `scientist.Run` returns `scientist.MismatchResult` as error,
 giving you access to all the information about the observations.
 
+Mismatched observations include the differences between the control value and their values
+in `Observation.Diff`, with paths like `.Items[3].Price`. Use `scientist.Diff` to compare
+any two values the same way. Experiments that clean their values only get the paths of
+the differences, without the raw values.
+
+Replaying runs
+
+The order of execution of the behaviors is randomized with a new seed on every run.
+The seed is recorded in `Result.Seed`, and the order in `Result.Order`.
+Use `Facts.SetSeed` to replay the order of a run, or `Facts.SetRandSource`
+to generate the seeds from your own random source:
+
+	experiment := scientist.NewQuickExperiment()
+	experiment.SetSeed(result.Seed)
+
+Recovering from panics
+
+Panics in the behaviors are recovered and recorded as `*scientist.PanicError` in their observations,
+with the panic value and the stack trace of the goroutine that panicked. Use `errors.As` to inspect them.
+Panics in the control behavior are also returned by `scientist.Run` as errors.
+Use `Facts.SetRepanicControl` to panic again on the goroutine that calls `scientist.Run` instead,
+like your program would do without the experiment:
+
+	experiment := scientist.NewQuickExperiment()
+	experiment.SetRepanicControl(true)
+
+Grouping mismatches
+
+Mismatched observations have a fingerprint in `Observation.Fingerprint`, derived from the paths
+and kinds of their differences and the types of their errors, so the same bug always produces
+the same fingerprint. Slice indexes and map keys are ignored in the paths. Use a `scientist.Aggregator` in your publishers to count mismatches
+by fingerprint and report only the new ones:
+
+	func (e *myExperiment) Publish(ctx context.Context, result scientist.Result) error {
+		for _, o := range e.aggregator.Add(result) {
+			log.Printf("new mismatch in %s: %v", result.Name(), o.Diff)
+		}
+		return nil
+	}
+
+Publishing results
+
+Besides the experiment's `Publish` method, results are published to the publishers attached
+with `Facts.AddPublisher`, and to the publishers registered for every experiment with
+`scientist.RegisterPublisher`. Publishers implement the `scientist.Publisher` interface,
+and `scientist.PublisherFunc` adapts ordinary functions:
+
+	experiment.AddPublisher(scientist.PublisherFunc(func(ctx context.Context, result scientist.Result) error {
+		log.Printf("experiment %s matches: %v", result.Name(), result.Matches())
+		return nil
+	}))
+
+	scientist.RegisterPublisher(metricsPublisher)
+
+All the publishers are called, even if some of them fail. Their panics are recovered
+and returned as `*scientist.PanicError`, and their errors are joined together.
+Use `scientist.MultiPublisher` to fan out results the same way in your own publishers.
+
+The `publishers/statsd` package sends the duration of every behavior, in milliseconds,
+and the outcome of every experiment to StatsD, with optional DogStatsD tags:
+
+	metricsPublisher, err := statsd.New("127.0.0.1:8125", statsd.Options{Tags: true})
+
+The `publishers/prometheus` package counts runs, matches, mismatches, ignored mismatches, errors,
+panics and timeouts of every behavior, and tracks their latency in histograms. It serves them
+in the Prometheus text exposition format:
+
+	metrics := prometheus.New(prometheus.Options{})
+	scientist.RegisterPublisher(metrics)
+	http.Handle("/metrics/scientist", metrics)
+
+Publishers run on the goroutine that calls `scientist.Run`. Wrap slow publishers with the `publishers/async`
+package to publish results in the background, with a bounded queue, and flush them on shutdown:
+
+	publisher := async.New(metricsPublisher, async.Options{Drop: async.DropOldest})
+	scientist.RegisterPublisher(publisher)
+	defer publisher.Shutdown(ctx)
+
+Handling publish errors
+
+Errors returned by the publishers never change the values returned by `scientist.Run`.
+They're handled by the experiment's `OnPublishError` method, if it has one, or by the global
+function `scientist.OnPublishError`, which logs them by default:
+
+	func (e *myExperiment) OnPublishError(ctx context.Context, result scientist.Result, err error) {
+		e.errors.Add(1)
+	}
+
+To make your tests fail when results can't be published, set the global variable
+`scientist.ErrorOnPublish` to `true`. `scientist.Run` returns the publish errors then,
+except for asynchronous experiments.
+
+Serializing results
+
+Results and observations can be encoded as JSON for your log pipeline. They include the name
+of the experiment, durations with units, errors with their types, panics with their stack traces,
+and which candidates mismatched, were ignored or timed out:
+
+	b, err := json.Marshal(result)
+
+The format is documented in `scientist.JSONSchemaVersion`, which changes when fields are removed
+or change their meaning. Decoded observations have the values decoded like `json.Unmarshal` does
+into an `interface{}`, and the errors decoded as `*scientist.JSONError` or `*scientist.PanicError`.
+
+The `publishers/jsonl` package publishes results as JSON Lines to any `io.Writer`,
+or to files that rotate by size and age:
+
+	scientist.RegisterPublisher(jsonl.New(os.Stdout, jsonl.Options{OmitValues: true}))
+
+Results and observations also implement `slog.LogValuer`, so they render as structured attributes
+with any `log/slog` handler. The `publishers/slogpub` package logs every result at a level
+that depends on its outcome, see `scientist.Result.Outcome`:
+
+	scientist.RegisterPublisher(slogpub.New(logger, slogpub.Options{
+		Levels: map[scientist.Outcome]slog.Level{
+			scientist.OutcomeMatched:    slog.LevelDebug,
+			scientist.OutcomeMismatched: slog.LevelError,
+		},
+	}))
+
+Running candidates in the background
+
+By default, `scientist.Run` waits for all the behaviors to finish before returning.
+Use `Facts.SetAsync` to return the control result as soon as it's ready.
+Candidates keep running in the background and results are published once they finish:
+
+	experiment := scientist.NewQuickExperiment()
+	experiment.SetAsync(true)
+
+Experiments with sequential strategies never run behaviors concurrently with the caller,
+so `scientist.Run` waits for all of them before returning, and only the results are published
+in the background.
+
+Keep in mind that candidates receive the same context as the control behavior,
+so they will observe its cancellation if it's canceled after `scientist.Run` returns.
+
+Skipping runs and preparing experiments
+
+Experiments can implement two optional methods to control each run, like in GitHub's ruby scientist.
+`RunIf` is called after `IsEnabled` with the context of the run, return false to run only the control behavior.
+`BeforeRun` is called only when the experiment runs its candidates, use it to perform expensive setup:
+
+	type userExperiment struct {
+		scientist.QuickExperiment
+	}
+
+	func (userExperiment) RunIf(ctx context.Context) bool {
+		return ctx.Value("user").(models.User).IsStaff
+	}
+
+	func (userExperiment) BeforeRun(ctx context.Context) {
+		warmUpCache(ctx)
+	}
+
+Cleaning values before publishing
+
+Experiments can implement the optional method `Clean` to transform the values
+returned by the behaviors before publishing them, for example, to keep only the fields
+that your publishers need to know about. `Compare` and `Ignore` always see the raw values,
+cleaned values are stored in `Observation.CleanedValue`:
+
+	func (userExperiment) Clean(value interface{}) interface{} {
+		return value.(models.User).Login
+	}
+
+Ignoring known issues
+
+Use `Facts.AddIgnoreRule` to ignore mismatches caused by known issues.
+Every rule has a name, and optionally a ticket and a description, so you know
+why observations are ignored:
+
+	experiment.AddIgnoreRule(scientist.IgnoreRule{
+		Name:   "rounding",
+		Ticket: "https://github.com/org/repo/issues/42",
+		Ignore: func(ctx context.Context, control, candidate *scientist.Observation) bool {
+			return math.Abs(control.Value.(float64)-candidate.Value.(float64)) < 0.01
+		},
+	})
+
+Ignored observations record the rule that ignored them in `Observation.IgnoredBy`,
+and `Result.IgnoreCounts` returns how many observations every rule ignored.
+The experiment's `Ignore` method is checked before the rules, and it's recorded
+as a rule called `scientist.IgnoreMethod`.
+
+Normalizing values before comparing them
+
+Use `Facts.AddNormalizer` to transform the values returned by the behaviors before comparing them,
+to remove noise like generated IDs or ordering. Normalizers run in the order they're added, and
+`Compare` and `Ignore` receive copies of the observations with the normalized values.
+The normalized values are stored in `Observation.NormalizedValue`:
+
+	experiment.AddNormalizer(
+		scientist.ReplaceStrings(regexp.MustCompile(`[0-9a-f-]{36}`), "<uuid>"),
+		scientist.SortSlices(),
+		scientist.RoundFloats(2),
+	)
+
+Nil slices and maps are different from empty ones when comparing values,
+add the `NilAsEmpty()` normalizer to consider them equal.
+
+Enabling experiments for a percentage of runs
+
+Use `Facts.SetSampler` to enable an experiment only for a percentage of its runs.
+Samplers can extract a key from the context, like a user ID, so the experiment
+is always enabled, or always disabled, for the same key:
+
+	experiment := scientist.NewQuickExperiment()
+	experiment.SetSampler(&scientist.Sampler{
+		Percent: 10,
+		Key:     scientist.ContextKey("user_id"),
+	})
+
+Keys are hashed with the experiment's name, so experiments with the same percentage
+are enabled for different keys. The sampling decision is recorded in `Result.Sampling`.
+
+Choosing how behaviors run
+
+By default, all the behaviors run at the same time, each one in its own goroutine.
+Use `Facts.SetStrategy` to run behaviors that are not safe to run concurrently
+one after the other, on the goroutine that calls `scientist.Run`:
+
+	experiment := scientist.NewQuickExperiment()
+	experiment.SetStrategy(scientist.ControlFirst)
+
+`scientist.Sequential` runs the behaviors in random order, `scientist.ControlFirst`
+runs the control before the candidates and `scientist.CandidatesFirst` runs the candidates
+before the control. Candidates always run in random order. The strategy and the order
+of execution are recorded in `Result.Strategy` and `Result.Order`.
+
+Disabling misbehaving candidates
+
+Use `Facts.SetCircuitBreaker` to stop running candidates that fail, panic or are
+much slower than the control. Every candidate has a circuit that opens when its last runs
+exceed any of the breaker's limits. Candidates with open circuits don't run until the
+breaker's cool down passes, then they run once to decide if their circuits can be closed again:
+
+	experiment := scientist.NewQuickExperiment()
+	experiment.SetCircuitBreaker(&scientist.CircuitBreaker{
+		Window:          100,
+		MaxErrorRate:    0.1,
+		MaxPanics:       1,
+		MaxLatencyRatio: 10,
+		CoolDown:        time.Minute,
+	})
+
+Candidates whose circuits open during a run are recorded in `Result.Tripped`,
+and candidates that don't run because their circuits are open are recorded in `Result.Skipped`.
+Results are published even when all the candidates are skipped, with only the control observation.
+
+Limiting how long candidates run
+
+Use `Facts.SetTimeout` to set a deadline for every candidate, or
+`Facts.SetCandidateTimeout` to set it for a specific candidate.
+The context given to a candidate is canceled when its deadline expires,
+and its observation is recorded as timed out. Timed out observations
+are not compared with the control, they're kept in `Result.TimedOut`:
+
+	experiment := scientist.NewQuickExperiment()
+	experiment.SetTimeout(100 * time.Millisecond)
+	experiment.SetCandidateTimeout("slow call", time.Second)
+
+The control behavior never times out. With sequential strategies, the next behavior
+doesn't start until a timed out candidate returns, so behaviors never run concurrently.
+Make sure your candidates stop when their context is canceled.
+
 Adding context information
 
 Giving extra information to your experiments is easy using a `context.Context` object.
@@ -69,12 +356,25 @@ of your context object to gather more information.
 	experiment.Use(control)
 	login, err := scientist.RunWithContext(ctx, experiment)
 
+Type-safe experiments
+
+The package `typed` provides generic versions of `Experiment`, `Behavior`,
+`Observation` and `Result`, so values flow from `Use` and `Try` through
+`Compare` and `Publish` without type assertions:
+
+	experiment := typed.NewQuickExperiment[[]string]()
+	experiment.Use(func(ctx context.Context) ([]string, error) {
+		return []string{"1", "2"}, nil
+	})
+	values, err := typed.Run[[]string](experiment)
+
 This package was inspired by GitHub's ruby scientist: https://github.com/github/scientist.
 */
 package scientist
 
 import (
-	"sync"
+	"log"
+	"runtime/debug"
 	"time"
 
 	"golang.org/x/net/context"
@@ -87,6 +387,19 @@ import (
 // intact in production.
 var ErrorOnMismatch = false
 
+// ErrorOnPublish tells scientist to return the errors
+// returned by the publishers instead of handling them.
+// Use this to make your tests fail when results
+// can't be published.
+var ErrorOnPublish = false
+
+// OnPublishError handles the errors returned by the publishers
+// of experiments that don't implement OnPublishError themselves,
+// see Experiment. By default, it logs them with the log package.
+var OnPublishError = func(ctx context.Context, result Result, err error) {
+	log.Printf("scientist: error publishing experiment %s: %v", result.Name(), err)
+}
+
 // Behavior is the type of function that defines how
 // your experiment behaves. See Experiment.Use and
 // Experiment.Try to set those behaviors.
@@ -94,7 +407,8 @@ type Behavior func(context.Context) (interface{}, error)
 
 // Run executes the experiment and publishes the results.
 // It always returns the result of the control behavior, unless
-// ErrorOnMismatch is true and there are mismatches.
+// ErrorOnMismatch is true and there are mismatches, or
+// ErrorOnPublish is true and the result can't be published.
 // The order of execution between control and candidates
 // is always random.
 func Run(e Experiment) (interface{}, error) {
@@ -104,9 +418,21 @@ func Run(e Experiment) (interface{}, error) {
 // RunWithContext executes the experiment and publishes the results.
 // It allows to set additional information via the context object.
 // It always returns the result of the control behavior, unless
-// ErrorOnMismatch is true and there are mismatches.
+// ErrorOnMismatch is true and there are mismatches, or
+// ErrorOnPublish is true and the result can't be published.
 // The order of execution between control and candidates
-// is always random.
+// is always random. How they run depends on the experiment's
+// Strategy, see Facts.SetStrategy. By default, they run in parallel.
+//
+// Asynchronous experiments, see Facts.SetAsync, return as soon as the
+// control behavior finishes, or once all the behaviors finish with
+// sequential strategies. Their results are published in the background
+// once all the candidates finish, so ErrorOnMismatch and ErrorOnPublish
+// don't apply to them, and panics comparing or cleaning their values
+// are handled as publish errors.
+//
+// Errors returned by the publishers are handled by the experiment's
+// OnPublishError method, if any, or by the global OnPublishError.
 func RunWithContext(ctx context.Context, e Experiment) (interface{}, error) {
 	c := e.Control()
 
@@ -114,21 +440,95 @@ func RunWithContext(ctx context.Context, e Experiment) (interface{}, error) {
 		return "", controlDoesNotExist{}
 	}
 
-	behaviors := e.Shuffle()
-
 	// run only the control behavior if the
-	// experiment is not enabled or there are
-	// no more behaviors.
-	if !e.IsEnabled(ctx) || len(behaviors) == 1 {
+	// experiment is not enabled for this run.
+	if !e.IsEnabled(ctx) || !runIf(ctx, e) {
 		return c(ctx)
 	}
 
-	control, candidates := runExperiment(ctx, e, behaviors)
+	var sampling *Sampling
+	if s := experimentSampler(e); s != nil {
+		sampled := s.Sample(ctx, e.Name())
+		if !sampled.Sampled {
+			return c(ctx)
+		}
+		sampling = &sampled
+	}
 
-	result := gatherResult(ctx, e, control, candidates)
+	seed, behaviors := shuffle(e)
 
-	if err := e.Publish(ctx, result); err != nil {
-		return nil, err
+	breaker := circuitBreaker(e)
+	var skipped []string
+	if breaker != nil {
+		behaviors, skipped = breaker.filter(behaviors)
+	}
+
+	// run only the control behavior if there are no candidates.
+	// When their circuits are open, the control still runs
+	// as part of the experiment, to publish the skipped ones.
+	if len(behaviors) == 1 && len(skipped) == 0 {
+		return c(ctx)
+	}
+
+	if len(behaviors) > 1 {
+		beforeRun(ctx, e)
+	}
+
+	strategy := experimentStrategy(e)
+	behaviors = strategy.order(behaviors)
+
+	control, finish := runExperiment(ctx, e, strategy, behaviors)
+
+	// sequential behaviors never run concurrently with the caller,
+	// so they all finish on its goroutine, even when the result
+	// is published in the background.
+	if strategy != Parallel {
+		candidates := finish()
+		finish = func() []*Observation { return candidates }
+	}
+
+	gather := func() Result {
+		candidates := finish()
+
+		// circuits are recorded before comparing the values,
+		// so panics in Compare, Ignore or the normalizers
+		// don't leave half open circuits waiting forever.
+		var tripped []string
+		if breaker != nil {
+			tripped = breaker.record(control, candidates)
+		}
+
+		result := gatherResult(ctx, e, control, candidates)
+		result.Strategy = strategy
+		result.Order = behaviors
+		result.Seed = seed
+		result.Sampling = sampling
+		result.Skipped = skipped
+		result.Tripped = tripped
+		return result
+	}
+
+	// the control panic is raised on the caller's goroutine
+	// without waiting for parallel candidates, as if the experiment
+	// was not enabled. The result is published in the background.
+	if p, ok := control.Error.(*PanicError); ok && repanicControl(e) {
+		go publishInBackground(ctx, e, control, gather)
+		panic(p.Value)
+	}
+
+	if isAsync(e) {
+		go publishInBackground(ctx, e, control, gather)
+		return control.Value, control.Error
+	}
+
+	result := gather()
+
+	if ErrorOnPublish {
+		if err := publish(ctx, e, result); err != nil {
+			return nil, err
+		}
+	} else {
+		publishOrHandle(ctx, e, result)
 	}
 
 	if ErrorOnMismatch && len(result.Mistmaches) > 0 {
@@ -138,26 +538,63 @@ func RunWithContext(ctx context.Context, e Experiment) (interface{}, error) {
 	return control.Value, control.Error
 }
 
-func runExperiment(ctx context.Context, e Experiment, behaviors []string) (*Observation, []*Observation) {
-	var control *Observation
+// publishInBackground gathers and publishes the result of an experiment
+// on a goroutine that nobody can recover. Panics in the experiment's code,
+// like Compare or Clean, are handled as publish errors, with a result
+// that only has the control observation.
+func publishInBackground(ctx context.Context, e Experiment, control *Observation, gather func() Result) {
+	defer func() {
+		if r := recover(); r != nil {
+			result := Result{name: e.Name(), Control: control}
+			onPublishError(ctx, e, result, &PanicError{Name: e.Name(), Value: r, Stack: debug.Stack()})
+		}
+	}()
+
+	publishOrHandle(ctx, e, gather())
+}
+
+// runExperiment runs the behaviors, in the given order, until the
+// control observation is ready. It returns the control observation and a
+// function that waits for the rest of the candidates to finish.
+// Parallel behaviors run each one in its own goroutine. Sequential
+// behaviors run on the goroutine that calls runExperiment until the control
+// finishes, the rest of them run on the goroutine that calls finish.
+func runExperiment(ctx context.Context, e Experiment, strategy Strategy, behaviors []string) (control *Observation, finish func() []*Observation) {
 	var candidates []*Observation
-	var wg sync.WaitGroup
 
-	finished := make(chan *Observation, len(behaviors))
+	if strategy == Parallel {
+		finished := make(chan *Observation, len(behaviors))
 
-	for _, name := range behaviors {
-		wg.Add(1)
-		go func(ctx context.Context, name string) {
-			defer wg.Done()
+		for _, name := range behaviors {
+			go func(ctx context.Context, name string) {
+				b := e.Behavior(name)
+				finished <- observeWithTimeout(ctx, name, b, timeout(e, name), false)
+			}(ctx, name)
+		}
 
-			b := e.Behavior(name)
-			finished <- observe(ctx, name, b)
-		}(ctx, name)
+		for control == nil {
+			o := <-finished
+			if o.Name == "__control__" {
+				control = o
+			} else {
+				candidates = append(candidates, o)
+			}
+		}
+
+		return control, func() []*Observation {
+			for len(candidates) < len(behaviors)-1 {
+				candidates = append(candidates, <-finished)
+			}
+			return candidates
+		}
 	}
-	wg.Wait()
-	close(finished)
 
-	for o := range finished {
+	next := 0
+	for control == nil {
+		name := behaviors[next]
+		next++
+
+		o := observeWithTimeout(ctx, name, e.Behavior(name), timeout(e, name), true)
 		if o.Name == "__control__" {
 			control = o
 		} else {
@@ -165,7 +602,52 @@ func runExperiment(ctx context.Context, e Experiment, behaviors []string) (*Obse
 		}
 	}
 
-	return control, candidates
+	return control, func() []*Observation {
+		for _, name := range behaviors[next:] {
+			candidates = append(candidates, observeWithTimeout(ctx, name, e.Behavior(name), timeout(e, name), true))
+		}
+		return candidates
+	}
+}
+
+// observeWithTimeout observes a behavior that must finish before the timeout.
+// The behavior's context is canceled when the timeout expires, and its
+// observation is recorded as timed out, even if the behavior ignores
+// the cancellation and keeps running. If wait is true, it returns once
+// the behavior returns, so sequential behaviors never run concurrently.
+func observeWithTimeout(ctx context.Context, name string, b Behavior, timeout time.Duration, wait bool) *Observation {
+	if timeout <= 0 {
+		return observe(ctx, name, b)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	finished := make(chan *Observation, 1)
+	go func() {
+		finished <- observe(ctx, name, b)
+	}()
+
+	select {
+	case o := <-finished:
+		return o
+	case <-ctx.Done():
+		o := &Observation{
+			Name:     name,
+			Start:    start,
+			Duration: time.Since(start),
+			Error:    ctx.Err(),
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			o.Error = behaviorTimedOut{name, timeout}
+			o.TimedOut = true
+		}
+		if wait {
+			<-finished
+		}
+		return o
+	}
 }
 
 func observe(ctx context.Context, name string, b Behavior) (obs *Observation) {
@@ -174,7 +656,7 @@ func observe(ctx context.Context, name string, b Behavior) (obs *Observation) {
 	}
 	defer func() {
 		if r := recover(); r != nil {
-			o.Error = recoverFromBadBehavior{name, r}
+			o.Error = &PanicError{Name: name, Value: r, Stack: debug.Stack()}
 			obs = o
 		}
 	}()
@@ -194,17 +676,54 @@ func gatherResult(ctx context.Context, e Experiment, control *Observation, candi
 		Candidates: candidates,
 	}
 
+	// experiments that clean their values don't want
+	// the raw values published in the differences either.
+	_, cleans := e.(cleanExperiment)
+	result.cleaned = cleans
+
+	normalizers := experimentNormalizers(e)
+	normalized := func(o *Observation) *Observation {
+		if len(normalizers) == 0 {
+			return o
+		}
+		o.NormalizedValue = normalize(normalizers, o.Value)
+
+		n := *o
+		n.Value = o.NormalizedValue
+		return &n
+	}
+
+	c := normalized(control)
 	for _, o := range candidates {
-		match := e.Compare(ctx, control, o)
+		if o.TimedOut {
+			result.TimedOut = append(result.TimedOut, o)
+			continue
+		}
+
+		n := normalized(o)
+		match := e.Compare(ctx, c, n)
 
 		if !match {
-			if e.Ignore(ctx, control, o) {
+			if rule := ignoredBy(ctx, e, c, n); rule != nil {
+				o.IgnoredBy = rule
 				result.Ignored = append(result.Ignored, o)
 				continue
 			}
+			o.Diff = Diff(c.Value, n.Value)
+			if cleans {
+				o.Diff = withoutValues(o.Diff)
+			}
+			o.Fingerprint = Fingerprint(control, o)
 			result.Mistmaches = append(result.Mistmaches, o)
 		}
 	}
 
+	// values are cleaned after the comparison,
+	// so Compare and Ignore never see cleaned values.
+	control.CleanedValue = clean(e, control.Value)
+	for _, o := range candidates {
+		o.CleanedValue = clean(e, o.Value)
+	}
+
 	return result
 }
@@ -1,8 +1,13 @@
 package scientist
 
 import (
+	"bytes"
+	"errors"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 )
@@ -118,7 +123,7 @@ func TestRunErrorOnMisMatch(t *testing.T) {
 
 	g := len(result.Mistmaches)
 	if g != 1 {
-		t.Fatalf("mismatches got %v, expected %v, %q", g, 1, result)
+		t.Fatalf("mismatches got %v, expected %v, %v", g, 1, result)
 	}
 }
 
@@ -147,6 +152,46 @@ func TestRunBadBehavior(t *testing.T) {
 	if !IsRecoverFromBadBehavior(m.Error) {
 		t.Fatalf("got %v, expected recoverFromBadBehavior", m.Error)
 	}
+
+	var p *PanicError
+	if !errors.As(m.Error, &p) {
+		t.Fatalf("got %v, expected *PanicError", m.Error)
+	}
+
+	if p.Value != "oh no!" || p.Name != "test" {
+		t.Fatalf("got panic %v in %s, expected oh no! in test", p.Value, p.Name)
+	}
+
+	if !bytes.Contains(p.Stack, []byte("TestRunBadBehavior")) {
+		t.Fatalf("expected stack trace to include the behavior, got %s", p.Stack)
+	}
+}
+
+func TestRunRepanicControl(t *testing.T) {
+	e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+	e.SetRepanicControl(true)
+
+	e.Use(func(ctx context.Context) (interface{}, error) {
+		panic("oh no!")
+	})
+
+	e.Try("test", func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	defer func() {
+		if r := recover(); r != "oh no!" {
+			t.Fatalf("recover got %v, expected %v", r, "oh no!")
+		}
+
+		r := <-e.published
+		if !IsRecoverFromBadBehavior(r.Control.Error) {
+			t.Fatalf("got %v, expected recoverFromBadBehavior", r.Control.Error)
+		}
+	}()
+
+	Run(e)
+	t.Fatal("expected control panic")
 }
 
 func TestRunDeepCompare(t *testing.T) {
@@ -173,3 +218,280 @@ func TestRunDeepCompare(t *testing.T) {
 		t.Fatalf("run got %v, expected %v", result, w)
 	}
 }
+
+type publishExperiment struct {
+	QuickExperiment
+	published chan Result
+}
+
+func (e publishExperiment) Publish(ctx context.Context, result Result) error {
+	e.published <- result
+	return nil
+}
+
+func TestRunAsync(t *testing.T) {
+	e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+	e.SetAsync(true)
+
+	release := make(chan struct{})
+
+	e.Use(func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	e.Try("test", func(_ context.Context) (interface{}, error) {
+		<-release
+		return "fail", nil
+	})
+
+	result, err := Run(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "success" {
+		t.Fatalf("run got %v, expected %v", result, "success")
+	}
+
+	select {
+	case <-e.published:
+		t.Fatal("expected result to be published after the candidate finished")
+	default:
+	}
+
+	close(release)
+
+	r := <-e.published
+	if len(r.Mistmaches) != 1 {
+		t.Fatalf("mismatches got %d, expected %d", len(r.Mistmaches), 1)
+	}
+}
+
+func TestRunSequentialTimeout(t *testing.T) {
+	e := NewQuickExperiment()
+	e.SetStrategy(Sequential)
+	e.SetTimeout(10 * time.Millisecond)
+
+	var mu sync.Mutex
+	running, max := 0, 0
+	behavior := func(ctx context.Context) (interface{}, error) {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mu.Unlock()
+
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, ctx.Err()
+	}
+
+	e.Use(func(ctx context.Context) (interface{}, error) {
+		return 1, nil
+	})
+	e.Try("a", behavior)
+	e.Try("b", behavior)
+	e.Try("c", behavior)
+
+	if _, err := Run(e); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if max != 1 {
+		t.Fatalf("expected behaviors to run one at a time, got %d at once", max)
+	}
+}
+
+func TestRunSequentialAsync(t *testing.T) {
+	for _, repanic := range []bool{false, true} {
+		e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+		e.SetStrategy(ControlFirst)
+		e.SetAsync(!repanic)
+		e.SetRepanicControl(repanic)
+
+		var finished atomic.Bool
+		e.Use(func(_ context.Context) (interface{}, error) {
+			if repanic {
+				panic("control")
+			}
+			return 1, nil
+		})
+		e.Try("test", func(_ context.Context) (interface{}, error) {
+			time.Sleep(10 * time.Millisecond)
+			finished.Store(true)
+			return 1, nil
+		})
+
+		func() {
+			defer func() { recover() }()
+			Run(e)
+		}()
+
+		if !finished.Load() {
+			t.Fatalf("repanic %v: expected the candidate to finish before Run returns", repanic)
+		}
+		<-e.published
+	}
+}
+
+func TestRunBackgroundPanics(t *testing.T) {
+	for _, repanic := range []bool{false, true} {
+		e := failingPublishExperiment{NewQuickExperiment(), make(chan error, 1)}
+		e.SetAsync(!repanic)
+		e.SetRepanicControl(repanic)
+		e.SetComparator(func(ctx context.Context, control, candidate *Observation) bool {
+			panic("bad comparator")
+		})
+
+		e.Use(func(_ context.Context) (interface{}, error) {
+			if repanic {
+				panic("oh no!")
+			}
+			return "success", nil
+		})
+
+		e.Try("test", func(_ context.Context) (interface{}, error) {
+			return "success", nil
+		})
+
+		func() {
+			defer func() {
+				if r := recover(); r != nil && !repanic {
+					t.Fatalf("unexpected panic: %v", r)
+				}
+			}()
+			Run(e)
+		}()
+
+		var p *PanicError
+		if err := <-e.errs; !errors.As(err, &p) || p.Value != "bad comparator" {
+			t.Fatalf("expected the panic to be handled as a publish error, got %v", err)
+		}
+	}
+}
+
+func TestRunCandidateTimeout(t *testing.T) {
+	e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+	e.SetTimeout(10 * time.Millisecond)
+	e.SetCandidateTimeout("slow", time.Minute)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	e.Use(func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	e.Try("hung", func(_ context.Context) (interface{}, error) {
+		<-release
+		return "success", nil
+	})
+
+	e.Try("slow", func(_ context.Context) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "success", nil
+	})
+
+	result, err := Run(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "success" {
+		t.Fatalf("run got %v, expected %v", result, "success")
+	}
+
+	r := <-e.published
+	if len(r.TimedOut) != 1 {
+		t.Fatalf("timed out got %d, expected %d", len(r.TimedOut), 1)
+	}
+
+	o := r.TimedOut[0]
+	if o.Name != "hung" || !IsTimedOut(o.Error) {
+		t.Fatalf("got %s with %v, expected hung to time out", o.Name, o.Error)
+	}
+
+	if len(r.Mistmaches) != 0 {
+		t.Fatalf("mismatches got %d, expected %d", len(r.Mistmaches), 0)
+	}
+}
+
+type lifecycleExperiment struct {
+	QuickExperiment
+	run       bool
+	beforeRun *int
+}
+
+func (e lifecycleExperiment) RunIf(ctx context.Context) bool {
+	return e.run
+}
+
+func (e lifecycleExperiment) BeforeRun(ctx context.Context) {
+	*e.beforeRun++
+}
+
+func TestRunIf(t *testing.T) {
+	var calls int
+	e := lifecycleExperiment{NewQuickExperiment(), false, &calls}
+
+	ErrorOnMismatch = true
+	defer func() { ErrorOnMismatch = false }()
+
+	e.Use(func(_ context.Context) (interface{}, error) {
+		return "success", nil
+	})
+
+	e.Try("test", func(_ context.Context) (interface{}, error) {
+		return "fail", nil
+	})
+
+	result, err := Run(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "success" {
+		t.Fatalf("run got %v, expected %v", result, "success")
+	}
+
+	if calls != 0 {
+		t.Fatalf("before run got %d calls, expected %d", calls, 0)
+	}
+
+	e.run = true
+	if _, err := Run(e); err == nil {
+		t.Fatal("expected mismatch error, got nil")
+	}
+
+	if calls != 1 {
+		t.Fatalf("before run got %d calls, expected %d", calls, 1)
+	}
+}
+
+func TestRunReplaySeed(t *testing.T) {
+	e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+	e.SetStrategy(Sequential)
+
+	e.Use(emptyBehavior)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		e.Try(name, emptyBehavior)
+	}
+
+	Run(e)
+	first := <-e.published
+
+	e.SetSeed(first.Seed)
+	Run(e)
+	replay := <-e.published
+
+	if !reflect.DeepEqual(first.Order, replay.Order) {
+		t.Fatalf("replay order got %v, expected %v", replay.Order, first.Order)
+	}
+}
@@ -0,0 +1,70 @@
+package scientist
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// LogValue implements slog.LogValuer. It logs the name and the outcome of
+// the experiment, the control observation and the candidate observations
+// grouped by their names. Values are not logged, use the differences.
+func (r Result) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("experiment", r.name),
+		slog.String("outcome", string(r.Outcome())),
+	}
+
+	if r.Control != nil {
+		attrs = append(attrs, slog.Any("control", r.Control))
+	}
+
+	if len(r.Candidates) > 0 {
+		candidates := make([]slog.Attr, len(r.Candidates))
+		for i, c := range r.Candidates {
+			candidates[i] = slog.Any(c.Name, c)
+		}
+		attrs = append(attrs, slog.Attr{Key: "candidates", Value: slog.GroupValue(candidates...)})
+	}
+
+	if len(r.Skipped) > 0 {
+		attrs = append(attrs, slog.Any("skipped", r.Skipped))
+	}
+
+	if len(r.Tripped) > 0 {
+		attrs = append(attrs, slog.Any("tripped", r.Tripped))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer. It logs the duration of the
+// behavior, its error and the summary of its differences, if any.
+func (o Observation) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Duration("duration", o.Duration),
+	}
+
+	if o.Error != nil {
+		attrs = append(attrs,
+			slog.String("error", o.Error.Error()),
+			slog.String("error_type", fmt.Sprintf("%T", o.Error)),
+		)
+	}
+
+	if o.TimedOut {
+		attrs = append(attrs, slog.Bool("timed_out", true))
+	}
+
+	if o.IgnoredBy != nil {
+		attrs = append(attrs, slog.String("ignored_by", o.IgnoredBy.Name))
+	}
+
+	if len(o.Diff) > 0 {
+		attrs = append(attrs,
+			slog.String("diff", formatDiff(o.Diff)),
+			slog.String("fingerprint", o.Fingerprint),
+		)
+	}
+
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,62 @@
+package scientist
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResultOutcome(t *testing.T) {
+	o := &Observation{}
+	cases := []struct {
+		result   Result
+		expected Outcome
+	}{
+		{Result{}, OutcomeMatched},
+		{Result{Ignored: []*Observation{o}}, OutcomeIgnored},
+		{Result{Ignored: []*Observation{o}, TimedOut: []*Observation{o}}, OutcomeTimedOut},
+		{Result{Mistmaches: []*Observation{o}, TimedOut: []*Observation{o}}, OutcomeMismatched},
+	}
+
+	for _, c := range cases {
+		if got := c.result.Outcome(); got != c.expected {
+			t.Fatalf("outcome: got %s, expected %s", got, c.expected)
+		}
+	}
+}
+
+func TestResultLogValue(t *testing.T) {
+	candidate := &Observation{
+		Name:        "candidate",
+		Duration:    time.Millisecond,
+		Diff:        []Difference{{Path: ".", Kind: Changed, Control: 1, Candidate: 2}},
+		Fingerprint: "abc",
+		IgnoredBy:   &IgnoreRule{Name: "rule"},
+	}
+	result := Result{
+		name:       "experiment",
+		Control:    &Observation{Name: "__control__", Duration: time.Second},
+		Candidates: []*Observation{candidate},
+		Ignored:    []*Observation{candidate},
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("result", "result", result)
+
+	expected := []string{
+		"result.experiment=experiment",
+		"result.outcome=ignored",
+		"result.control.duration=1s",
+		"result.candidates.candidate.duration=1ms",
+		"result.candidates.candidate.ignored_by=rule",
+		`result.candidates.candidate.diff=".: 1 != 2"`,
+		"result.candidates.candidate.fingerprint=abc",
+	}
+	for _, e := range expected {
+		if !strings.Contains(buf.String(), e) {
+			t.Fatalf("expected %s in %s", e, buf.String())
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package scientist
+
+import "fmt"
+
+// Strategy defines how an experiment runs its behaviors.
+type Strategy int
+
+const (
+	// Parallel runs every behavior in its own goroutine, at the same time.
+	Parallel Strategy = iota
+	// Sequential runs the behaviors one after the other in random order.
+	Sequential
+	// ControlFirst runs the control behavior and then
+	// the candidates one after the other in random order.
+	ControlFirst
+	// CandidatesFirst runs the candidates one after the other
+	// in random order and then the control behavior.
+	CandidatesFirst
+)
+
+// String returns the name of the strategy.
+func (s Strategy) String() string {
+	switch s {
+	case Parallel:
+		return "parallel"
+	case Sequential:
+		return "sequential"
+	case ControlFirst:
+		return "control first"
+	case CandidatesFirst:
+		return "candidates first"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the strategy as its name.
+func (s Strategy) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a strategy from its name.
+func (s *Strategy) UnmarshalText(text []byte) error {
+	for _, strategy := range []Strategy{Parallel, Sequential, ControlFirst, CandidatesFirst} {
+		if strategy.String() == string(text) {
+			*s = strategy
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown strategy: %s", text)
+}
+
+// order returns the order of execution of the
+// shuffled behaviors for the strategy.
+func (s Strategy) order(behaviors []string) []string {
+	if s != ControlFirst && s != CandidatesFirst {
+		return append([]string(nil), behaviors...)
+	}
+
+	order := make([]string, 0, len(behaviors))
+	for _, name := range behaviors {
+		if name != "__control__" {
+			order = append(order, name)
+		}
+	}
+
+	if s == ControlFirst {
+		return append([]string{"__control__"}, order...)
+	}
+	return append(order, "__control__")
+}
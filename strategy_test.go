@@ -0,0 +1,82 @@
+package scientist
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestStrategyOrder(t *testing.T) {
+	behaviors := []string{"a", "__control__", "b"}
+
+	cases := []struct {
+		strategy Strategy
+		order    []string
+	}{
+		{Parallel, []string{"a", "__control__", "b"}},
+		{Sequential, []string{"a", "__control__", "b"}},
+		{ControlFirst, []string{"__control__", "a", "b"}},
+		{CandidatesFirst, []string{"a", "b", "__control__"}},
+	}
+
+	for _, c := range cases {
+		o := c.strategy.order(behaviors)
+		if !reflect.DeepEqual(o, c.order) {
+			t.Fatalf("%s order: got %v, expected %v", c.strategy, o, c.order)
+		}
+	}
+}
+
+func TestRunSequential(t *testing.T) {
+	for _, s := range []Strategy{Sequential, ControlFirst, CandidatesFirst} {
+		e := publishExperiment{NewQuickExperiment(), make(chan Result, 1)}
+		e.SetStrategy(s)
+
+		var running, overlaps int32
+		behavior := func(v interface{}) Behavior {
+			return func(_ context.Context) (interface{}, error) {
+				if atomic.AddInt32(&running, 1) > 1 {
+					atomic.AddInt32(&overlaps, 1)
+				}
+				defer atomic.AddInt32(&running, -1)
+				return v, nil
+			}
+		}
+
+		e.Use(behavior("success"))
+		e.Try("a", behavior("success"))
+		e.Try("b", behavior("success"))
+
+		result, err := Run(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != "success" {
+			t.Fatalf("%s run got %v, expected %v", s, result, "success")
+		}
+
+		if overlaps != 0 {
+			t.Fatalf("%s got %d behaviors running concurrently", s, overlaps)
+		}
+
+		r := <-e.published
+		if r.Strategy != s {
+			t.Fatalf("strategy got %s, expected %s", r.Strategy, s)
+		}
+
+		if len(r.Order) != 3 || len(r.Candidates) != 2 {
+			t.Fatalf("%s got order %v and %d candidates", s, r.Order, len(r.Candidates))
+		}
+
+		if s == ControlFirst && r.Order[0] != "__control__" {
+			t.Fatalf("%s got order %v", s, r.Order)
+		}
+
+		if s == CandidatesFirst && r.Order[2] != "__control__" {
+			t.Fatalf("%s got order %v", s, r.Order)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package typed
+
+import (
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// Experiment is an interface that defines
+// how an experiment with values of type T behaves.
+//
+// Experiments can implement the same optional methods
+// as scientist.Experiment. Clean and OnPublishError receive typed values:
+//
+//	Clean(value T) interface{}
+//	OnPublishError(ctx context.Context, result Result[T], err error)
+type Experiment[T any] interface {
+	Name() string
+	Control() Behavior[T]
+	Shuffle() []string
+	Behavior(name string) Behavior[T]
+	IsEnabled(ctx context.Context) bool
+	Ignore(ctx context.Context, control, candidate *Observation[T]) bool
+	Compare(ctx context.Context, control, candidate *Observation[T]) bool
+	Publish(ctx context.Context, result Result[T]) error
+}
+
+// QuickExperiment is an experiment with a very basic behavior.
+// It's always enabled and it does not publishes results anywhere.
+type QuickExperiment[T any] struct {
+	*Facts[T]
+}
+
+// NewQuickExperiment creates a new Experiment for values of type T.
+func NewQuickExperiment[T any]() QuickExperiment[T] {
+	return QuickExperiment[T]{
+		Facts: NewFacts[T](),
+	}
+}
+
+// IsEnabled returns true if the experiment is enabled.
+// If it's not enabled, the experiment only runs the
+// control behavior.
+func (e QuickExperiment[T]) IsEnabled(ctx context.Context) bool {
+	return true
+}
+
+// Ignore returns true if a candidate behavior can be ignored.
+// By default there are no behaviors ignored.
+func (e QuickExperiment[T]) Ignore(ctx context.Context, control, candidate *Observation[T]) bool {
+	return false
+}
+
+// Compare returns true if the result of the control behavior is the same
+// as the result of a candidate behavior.
+// It uses the same comparison as scientist.QuickExperiment.
+func (e QuickExperiment[T]) Compare(ctx context.Context, control, candidate *Observation[T]) bool {
+	return scientist.QuickExperiment{Facts: e.Facts.Facts}.Compare(ctx, control.untyped(), candidate.untyped())
+}
+
+// Publish allows you to export the result of the experiment somewhere else.
+// Use it to compare result information between control and candidates.
+func (e QuickExperiment[T]) Publish(ctx context.Context, result Result[T]) error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package typed
+
+import "github.com/calavera/go-scientist"
+
+// Facts holds behavior information for an experiment
+// with values of type T.
+type Facts[T any] struct {
+	*scientist.Facts
+}
+
+// NewFacts create a new set of facts for a experiment.
+func NewFacts[T any]() *Facts[T] {
+	return &Facts[T]{
+		Facts: scientist.NewFacts(),
+	}
+}
+
+// Control returns the control behavior.
+func (f *Facts[T]) Control() Behavior[T] {
+	return typed[T](f.Facts.Control())
+}
+
+// Behavior returns a candidate behavior by its name.
+func (f *Facts[T]) Behavior(name string) Behavior[T] {
+	return typed[T](f.Facts.Behavior(name))
+}
+
+// Use sets the control behavior.
+func (f *Facts[T]) Use(behavior Behavior[T]) error {
+	return f.Facts.Use(untyped(behavior))
+}
+
+// Try adds a new candidate behavior.
+// The name of each candidate must be unique.
+func (f *Facts[T]) Try(name string, behavior Behavior[T]) error {
+	return f.Facts.Try(name, untyped(behavior))
+}
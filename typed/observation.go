@@ -0,0 +1,56 @@
+package typed
+
+import (
+	"encoding/json"
+
+	"github.com/calavera/go-scientist"
+)
+
+// Observation holds information about
+// an executed behavior that returns values of type T.
+// The embedded scientist.Observation keeps the untyped value.
+type Observation[T any] struct {
+	scientist.Observation
+	// Value is the value returned by the behavior if any.
+	Value T
+}
+
+func newObservation[T any](o *scientist.Observation) *Observation[T] {
+	if o == nil {
+		return nil
+	}
+	t, _ := o.Value.(T)
+	return &Observation[T]{
+		Observation: *o,
+		Value:       t,
+	}
+}
+
+func (o *Observation[T]) untyped() *scientist.Observation {
+	u := o.Observation
+	u.Value = o.Value
+	return &u
+}
+
+// UnmarshalJSON decodes an observation encoded with
+// scientist.Observation.MarshalJSON, decoding the value into T.
+func (o *Observation[T]) UnmarshalJSON(data []byte) error {
+	if err := o.Observation.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	return decodeValue(o.Observation.Value, &o.Value)
+}
+
+// decodeValue decodes a value decoded from JSON into an interface{} into T.
+func decodeValue[T any](value interface{}, t *T) error {
+	*t = *new(T)
+	if value == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, t)
+}
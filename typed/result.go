@@ -0,0 +1,76 @@
+package typed
+
+import "github.com/calavera/go-scientist"
+
+// Result holds information about an executed
+// experiment with values of type T.
+// The embedded scientist.Result keeps the untyped observations.
+type Result[T any] struct {
+	scientist.Result
+	// Control is the result of executing the control behavior.
+	Control *Observation[T]
+	// Candidates are the results of executing all the candidate behaviors.
+	Candidates []*Observation[T]
+	// Mismatches are the results of behaviors that don't match the control.
+	Mistmaches []*Observation[T]
+	// Ignored are the results of behaviors that can be ignored.
+	Ignored []*Observation[T]
+	// TimedOut are the results of behaviors that didn't finish before their timeout.
+	TimedOut []*Observation[T]
+}
+
+func newResult[T any](r scientist.Result) Result[T] {
+	seen := make(map[*scientist.Observation]*Observation[T])
+	convert := func(os []*scientist.Observation) []*Observation[T] {
+		if os == nil {
+			return nil
+		}
+		ts := make([]*Observation[T], len(os))
+		for i, o := range os {
+			ts[i] = observation(seen, o)
+		}
+		return ts
+	}
+
+	return Result[T]{
+		Result:     r,
+		Control:    observation(seen, r.Control),
+		Candidates: convert(r.Candidates),
+		Mistmaches: convert(r.Mistmaches),
+		Ignored:    convert(r.Ignored),
+		TimedOut:   convert(r.TimedOut),
+	}
+}
+
+// UnmarshalJSON decodes a result encoded with scientist.Result.MarshalJSON,
+// decoding the values of the observations into T.
+func (r *Result[T]) UnmarshalJSON(data []byte) error {
+	var u scientist.Result
+	if err := u.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	*r = newResult[T](u)
+
+	// the rest of the lists point to the same candidates.
+	for _, o := range append([]*Observation[T]{r.Control}, r.Candidates...) {
+		if o == nil {
+			continue
+		}
+		if err := decodeValue(o.Observation.Value, &o.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// observation converts an observation only once, so the same
+// candidate is the same pointer in every list of the result.
+func observation[T any](seen map[*scientist.Observation]*Observation[T], o *scientist.Observation) *Observation[T] {
+	t, ok := seen[o]
+	if !ok {
+		t = newObservation[T](o)
+		seen[o] = t
+	}
+	return t
+}
@@ -0,0 +1,232 @@
+/*
+Package typed is a type-safe version of the scientist API.
+
+Experiments, behaviors, observations and results are parameterized
+by the type returned by the control and candidate behaviors, so values
+flow from Use and Try through Compare and Publish without type assertions:
+
+	experiment := typed.NewQuickExperiment[[]string]()
+
+	experiment.Use(func(ctx context.Context) ([]string, error) {
+		return []string{"1", "2"}, nil
+	})
+
+	experiment.Try("new code", func(ctx context.Context) ([]string, error) {
+		return []string{"1", "2"}, nil
+	})
+
+	values, err := typed.Run(experiment)
+
+Experiments are executed by the scientist package, so they behave exactly
+like their interface based counterparts.
+*/
+package typed
+
+import (
+	"time"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+// Behavior is the type of function that defines how
+// your experiment behaves. See Experiment.Use and
+// Experiment.Try to set those behaviors.
+type Behavior[T any] func(context.Context) (T, error)
+
+// Run executes the experiment and publishes the results.
+// It always returns the result of the control behavior, unless
+// scientist.ErrorOnMismatch is true and there are mismatches, or
+// scientist.ErrorOnPublish is true and the result can't be published.
+func Run[T any](e Experiment[T]) (T, error) {
+	return RunWithContext(context.Background(), e)
+}
+
+// RunWithContext executes the experiment and publishes the results.
+// It allows to set additional information via the context object.
+// It always returns the result of the control behavior, unless
+// scientist.ErrorOnMismatch is true and there are mismatches, or
+// scientist.ErrorOnPublish is true and the result can't be published.
+func RunWithContext[T any](ctx context.Context, e Experiment[T]) (T, error) {
+	v, err := scientist.RunWithContext(ctx, adapt(e))
+	t, _ := v.(T)
+	return t, err
+}
+
+// adapt adapts an Experiment[T] to the scientist.Experiment interface.
+// The adapter only has a Clean method if the experiment cleans its values,
+// because the scientist package leaves raw values out of the differences
+// of experiments that clean them.
+func adapt[T any](e Experiment[T]) scientist.Experiment {
+	if _, ok := e.(interface{ Clean(T) interface{} }); ok {
+		return cleanExperiment[T]{experiment[T]{e}}
+	}
+	return experiment[T]{e}
+}
+
+// experiment adapts an Experiment[T] to
+// the scientist.Experiment interface.
+type experiment[T any] struct {
+	e Experiment[T]
+}
+
+// cleanExperiment adapts an Experiment[T]
+// that cleans its values with Clean(T).
+type cleanExperiment[T any] struct {
+	experiment[T]
+}
+
+func (a experiment[T]) Name() string {
+	return a.e.Name()
+}
+
+func (a experiment[T]) Control() scientist.Behavior {
+	return untyped(a.e.Control())
+}
+
+func (a experiment[T]) Shuffle() []string {
+	return a.e.Shuffle()
+}
+
+func (a experiment[T]) Behavior(name string) scientist.Behavior {
+	return untyped(a.e.Behavior(name))
+}
+
+func (a experiment[T]) IsEnabled(ctx context.Context) bool {
+	return a.e.IsEnabled(ctx)
+}
+
+func (a experiment[T]) Ignore(ctx context.Context, control, candidate *scientist.Observation) bool {
+	return a.e.Ignore(ctx, newObservation[T](control), newObservation[T](candidate))
+}
+
+func (a experiment[T]) Compare(ctx context.Context, control, candidate *scientist.Observation) bool {
+	return a.e.Compare(ctx, newObservation[T](control), newObservation[T](candidate))
+}
+
+func (a experiment[T]) Publish(ctx context.Context, result scientist.Result) error {
+	return a.e.Publish(ctx, newResult[T](result))
+}
+
+func (a experiment[T]) NextSeed() int64 {
+	if x, ok := a.e.(interface{ NextSeed() int64 }); ok {
+		return x.NextSeed()
+	}
+	return 0
+}
+
+func (a experiment[T]) ShuffleWithSeed(seed int64) []string {
+	if x, ok := a.e.(interface{ ShuffleWithSeed(int64) []string }); ok {
+		return x.ShuffleWithSeed(seed)
+	}
+	return a.e.Shuffle()
+}
+
+func (a experiment[T]) RunIf(ctx context.Context) bool {
+	x, ok := a.e.(interface{ RunIf(context.Context) bool })
+	return !ok || x.RunIf(ctx)
+}
+
+func (a experiment[T]) BeforeRun(ctx context.Context) {
+	if x, ok := a.e.(interface{ BeforeRun(context.Context) }); ok {
+		x.BeforeRun(ctx)
+	}
+}
+
+func (a cleanExperiment[T]) Clean(value interface{}) interface{} {
+	t, _ := value.(T)
+	return a.e.(interface{ Clean(T) interface{} }).Clean(t)
+}
+
+func (a experiment[T]) OnPublishError(ctx context.Context, result scientist.Result, err error) {
+	if x, ok := a.e.(interface {
+		OnPublishError(context.Context, Result[T], error)
+	}); ok {
+		x.OnPublishError(ctx, newResult[T](result), err)
+		return
+	}
+	scientist.OnPublishError(ctx, result, err)
+}
+
+func (a experiment[T]) RepanicControl() bool {
+	x, ok := a.e.(interface{ RepanicControl() bool })
+	return ok && x.RepanicControl()
+}
+
+func (a experiment[T]) Async() bool {
+	x, ok := a.e.(interface{ Async() bool })
+	return ok && x.Async()
+}
+
+func (a experiment[T]) Timeout(name string) time.Duration {
+	if x, ok := a.e.(interface{ Timeout(string) time.Duration }); ok {
+		return x.Timeout(name)
+	}
+	return 0
+}
+
+func (a experiment[T]) Strategy() scientist.Strategy {
+	if x, ok := a.e.(interface{ Strategy() scientist.Strategy }); ok {
+		return x.Strategy()
+	}
+	return scientist.Parallel
+}
+
+func (a experiment[T]) Sampler() *scientist.Sampler {
+	if x, ok := a.e.(interface{ Sampler() *scientist.Sampler }); ok {
+		return x.Sampler()
+	}
+	return nil
+}
+
+func (a experiment[T]) CircuitBreaker() *scientist.CircuitBreaker {
+	if x, ok := a.e.(interface {
+		CircuitBreaker() *scientist.CircuitBreaker
+	}); ok {
+		return x.CircuitBreaker()
+	}
+	return nil
+}
+
+func (a experiment[T]) Normalizers() []scientist.Normalizer {
+	if x, ok := a.e.(interface{ Normalizers() []scientist.Normalizer }); ok {
+		return x.Normalizers()
+	}
+	return nil
+}
+
+func (a experiment[T]) IgnoreRules() []scientist.IgnoreRule {
+	if x, ok := a.e.(interface{ IgnoreRules() []scientist.IgnoreRule }); ok {
+		return x.IgnoreRules()
+	}
+	return nil
+}
+
+func (a experiment[T]) Publishers() []scientist.Publisher {
+	if x, ok := a.e.(interface{ Publishers() []scientist.Publisher }); ok {
+		return x.Publishers()
+	}
+	return nil
+}
+
+// untyped converts a typed behavior into a scientist.Behavior.
+func untyped[T any](b Behavior[T]) scientist.Behavior {
+	if b == nil {
+		return nil
+	}
+	return func(ctx context.Context) (interface{}, error) {
+		return b(ctx)
+	}
+}
+
+// typed converts a scientist.Behavior into a typed behavior.
+func typed[T any](b scientist.Behavior) Behavior[T] {
+	if b == nil {
+		return nil
+	}
+	return func(ctx context.Context) (T, error) {
+		v, err := b(ctx)
+		t, _ := v.(T)
+		return t, err
+	}
+}
@@ -0,0 +1,201 @@
+package typed
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/calavera/go-scientist"
+	"golang.org/x/net/context"
+)
+
+type deepEqualExperiment struct {
+	QuickExperiment[[]string]
+}
+
+func (deepEqualExperiment) Compare(ctx context.Context, control, candidate *Observation[[]string]) bool {
+	return reflect.DeepEqual(control.Value, candidate.Value)
+}
+
+type publishExperiment struct {
+	QuickExperiment[int]
+	result Result[int]
+}
+
+func (e *publishExperiment) Publish(ctx context.Context, result Result[int]) error {
+	e.result = result
+	return nil
+}
+
+func TestRunControl(t *testing.T) {
+	e := NewQuickExperiment[string]()
+
+	e.Use(func(_ context.Context) (string, error) {
+		return "success", nil
+	})
+
+	result, err := Run[string](e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "success" {
+		t.Fatalf("run got %v, expected %v", result, "success")
+	}
+}
+
+func TestRunWithoutControl(t *testing.T) {
+	e := NewQuickExperiment[string]()
+
+	_, err := Run[string](e)
+	if !scientist.IsControlNotExist(err) {
+		t.Fatalf("got %v, expected controlDoesNotExist", err)
+	}
+}
+
+func TestRunDeepCompare(t *testing.T) {
+	e := deepEqualExperiment{NewQuickExperiment[[]string]()}
+
+	scientist.ErrorOnMismatch = true
+	defer func() { scientist.ErrorOnMismatch = false }()
+
+	e.Use(func(_ context.Context) ([]string, error) {
+		return []string{"1", "2"}, nil
+	})
+
+	e.Try("test", func(_ context.Context) ([]string, error) {
+		return []string{"1", "2"}, nil
+	})
+
+	result, err := Run[[]string](e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := []string{"1", "2"}
+	if !reflect.DeepEqual(result, w) {
+		t.Fatalf("run got %v, expected %v", result, w)
+	}
+}
+
+func TestRunPublishTypedResult(t *testing.T) {
+	e := &publishExperiment{QuickExperiment: NewQuickExperiment[int]()}
+
+	e.Use(func(_ context.Context) (int, error) {
+		return 1, nil
+	})
+
+	e.Try("test", func(_ context.Context) (int, error) {
+		return 2, nil
+	})
+
+	result, err := Run[int](e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != 1 {
+		t.Fatalf("run got %v, expected %v", result, 1)
+	}
+
+	r := e.result
+	if r.Control.Value != 1 {
+		t.Fatalf("control got %v, expected %v", r.Control.Value, 1)
+	}
+
+	if len(r.Mistmaches) != 1 {
+		t.Fatalf("mismatches got %d, expected %d", len(r.Mistmaches), 1)
+	}
+
+	m := r.Mistmaches[0]
+	if m.Value != 2 {
+		t.Fatalf("mismatch got %v, expected %v", m.Value, 2)
+	}
+
+	if m != r.Candidates[0] {
+		t.Fatal("expected mismatch to be the same observation as the candidate")
+	}
+}
+
+type mapExperiment struct {
+	QuickExperiment[map[string]int]
+	result Result[map[string]int]
+}
+
+func (e *mapExperiment) Publish(ctx context.Context, result Result[map[string]int]) error {
+	e.result = result
+	return nil
+}
+
+type cleanMapExperiment struct {
+	mapExperiment
+}
+
+func (e *cleanMapExperiment) Clean(value map[string]int) interface{} {
+	return len(value)
+}
+
+func TestRunDiffValues(t *testing.T) {
+	behaviors := func(e QuickExperiment[map[string]int]) {
+		e.Use(func(_ context.Context) (map[string]int, error) {
+			return map[string]int{"a": 1}, nil
+		})
+		e.Try("test", func(_ context.Context) (map[string]int, error) {
+			return map[string]int{"a": 2}, nil
+		})
+	}
+
+	e := &mapExperiment{QuickExperiment: NewQuickExperiment[map[string]int]()}
+	behaviors(e.QuickExperiment)
+	if _, err := Run[map[string]int](e); err != nil {
+		t.Fatal(err)
+	}
+
+	d := e.result.Mistmaches[0].Diff
+	if len(d) != 1 || d[0].Control != 1 || d[0].Candidate != 2 {
+		t.Fatalf("diff got %v, expected the values of the behaviors", d)
+	}
+
+	c := &cleanMapExperiment{mapExperiment{QuickExperiment: NewQuickExperiment[map[string]int]()}}
+	behaviors(c.QuickExperiment)
+	if _, err := Run[map[string]int](c); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := c.result.Control.CleanedValue; v != 1 {
+		t.Fatalf("cleaned value got %v, expected %v", v, 1)
+	}
+
+	d = c.result.Mistmaches[0].Diff
+	if len(d) != 1 || d[0].Control != nil || d[0].Candidate != nil {
+		t.Fatalf("diff got %v, expected no values for a cleaned experiment", d)
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	e := &publishExperiment{QuickExperiment: NewQuickExperiment[int]()}
+	e.Use(func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	e.Try("candidate", func(ctx context.Context) (int, error) {
+		return 2, nil
+	})
+
+	if _, err := Run[int](e); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(e.result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Result[int]
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Name() != e.Name() || decoded.Control.Value != 1 || decoded.Mistmaches[0].Value != 2 {
+		t.Fatalf("expected typed values to be decoded, got %+v", decoded)
+	}
+}